@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"tuistr/model"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var ErrAuthNotAllowed = errors.New("auth not allowed for this relay yet")
+
+// newPool creates the relay pool. Relays that answer a request with auth-required
+// get a NIP-42 AUTH event signed by authenticate, after which the pool retries
+// the publish or subscription once.
+func (c *NostrClient) newPool(ctx context.Context) *nostr.SimplePool {
+	return nostr.NewSimplePool(ctx, nostr.WithAuthHandler(c.authenticate))
+}
+
+// authenticate signs a relay's AUTH challenge with the user's signer. Whether the
+// relay accepted it only shows once the retried request succeeds or fails.
+func (c *NostrClient) authenticate(ctx context.Context, authEvent nostr.RelayEvent) error {
+	url := authEvent.Relay.URL
+	if !c.authAllowed(url) {
+		c.health.setAuth(url, model.AuthRequired, ErrAuthNotAllowed)
+		return ErrAuthNotAllowed
+	}
+	if c.signer == nil {
+		c.health.setAuth(url, model.AuthRequired, ErrNoPrivateKey)
+		return ErrNoPrivateKey
+	}
+
+	if err := c.signer.SignEvent(ctx, authEvent.Event); err != nil {
+		state := model.AuthFailed
+		if errors.Is(err, ErrSignerLocked) {
+			state = model.AuthRequired
+		}
+		c.health.setAuth(url, state, err)
+		return err
+	}
+
+	c.health.setAuth(url, model.AuthPending, nil)
+	return nil
+}
+
+// authAllowed reports whether we answer AUTH challenges from url by ourselves:
+// the configured relays and the ones in our own relay list. AUTH tells a relay who
+// we are, so any other relay has to be allowed by the user first.
+func (c *NostrClient) authAllowed(url string) bool {
+	url = nostr.NormalizeURL(url)
+	if c.health.authAllowed(url) {
+		return true
+	}
+
+	c.relaysMu.RLock()
+	configured := slices.ContainsFunc(c.relays, sameRelay(url)) || slices.ContainsFunc(c.writeRelays, sameRelay(url))
+	c.relaysMu.RUnlock()
+	if configured {
+		return true
+	}
+
+	if pubKey := c.PubKey(); pubKey != "" {
+		list, _ := c.relayLists.get(pubKey)
+		return slices.Contains(list.Read, url) || slices.Contains(list.Write, url)
+	}
+	return false
+}
+
+// AllowRelayAuth lets url authenticate us for the rest of the session. The next
+// request that the relay refuses is retried with AUTH.
+func (c *NostrClient) AllowRelayAuth(url string) {
+	c.health.allowAuth(url)
+}
+
+// isAuthError reports whether a publish failed because the relay wanted AUTH, or
+// rejected the AUTH we sent.
+func isAuthError(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "failed to auth") || strings.Contains(msg, "auth-required:")
+}
+
+// authFailed is the state after a relay still refused us over auth.
+func authFailed(state model.AuthState) model.AuthState {
+	switch state {
+	case model.AuthRequired:
+		// we had no key to answer with, which is not the relay rejecting us
+		return model.AuthRequired
+	case model.AuthPending, model.AuthOK, model.AuthFailed:
+		return model.AuthFailed
+	default:
+		return model.AuthRequired
+	}
+}
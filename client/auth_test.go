@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"tuistr/model"
+
+	"github.com/coder/websocket"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// authRelay is an in-process relay that refuses REQ and EVENT until the
+// connection has answered its AUTH challenge.
+type authRelay struct {
+	mu     sync.Mutex
+	events []nostr.Event
+	authed []string
+}
+
+func (r *authRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	conn, err := websocket.Accept(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	ctx := req.Context()
+	send := func(env nostr.Envelope) {
+		data, _ := json.Marshal(env)
+		conn.Write(ctx, websocket.MessageText, data)
+	}
+
+	challenge := nostr.GeneratePrivateKey()[:16]
+	send(&nostr.AuthEnvelope{Challenge: &challenge})
+
+	authed := false
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		switch env := nostr.ParseMessage(string(data)).(type) {
+		case *nostr.AuthEnvelope:
+			evt := env.Event
+			ok, _ := evt.CheckSignature()
+			ok = ok && evt.Kind == nostr.KindClientAuthentication && evt.Tags.FindWithValue("challenge", challenge) != nil
+			if ok {
+				authed = true
+				r.mu.Lock()
+				r.authed = append(r.authed, evt.PubKey)
+				r.mu.Unlock()
+			}
+			send(&nostr.OKEnvelope{EventID: evt.ID, OK: ok, Reason: "restricted: bad auth event"})
+
+		case *nostr.EventEnvelope:
+			if !authed {
+				send(&nostr.OKEnvelope{EventID: env.ID, OK: false, Reason: "auth-required: members only"})
+				continue
+			}
+			r.mu.Lock()
+			r.events = append(r.events, env.Event)
+			r.mu.Unlock()
+			send(&nostr.OKEnvelope{EventID: env.ID, OK: true})
+
+		case *nostr.ReqEnvelope:
+			if !authed {
+				send(&nostr.ClosedEnvelope{SubscriptionID: env.SubscriptionID, Reason: "auth-required: members only"})
+				continue
+			}
+			r.mu.Lock()
+			for _, evt := range r.events {
+				if env.Filters.Match(&evt) {
+					send(&nostr.EventEnvelope{SubscriptionID: &env.SubscriptionID, Event: evt})
+				}
+			}
+			r.mu.Unlock()
+			eose := nostr.EOSEEnvelope(env.SubscriptionID)
+			send(&eose)
+		}
+	}
+}
+
+func newAuthTestClient(t *testing.T, url, privKey string) *NostrClient {
+	t.Helper()
+
+	c := &NostrClient{
+		relays:       []string{url},
+		writeRelays:  []string{url},
+		health:       newRelayHealth([]string{url}),
+		timeout:      5 * time.Second,
+		limit:        50,
+		relayLists:   newSimpleCache[relayList](),
+		countSupport: newSimpleCache[bool](),
+	}
+	if privKey != "" {
+		signer, err := newLocalSigner(privKey)
+		if err != nil {
+			t.Fatalf("invalid test key: %v", err)
+		}
+		c.signer = signer
+	}
+	c.pool = c.newPool(context.Background())
+	c.outbox = newOutboxPool(maxOutboxRelays, c.isConfiguredRelay, c.closeRelay)
+	t.Cleanup(func() { c.pool.Close("test done") })
+	return c
+}
+
+func startAuthRelay(t *testing.T) (*authRelay, string) {
+	t.Helper()
+	relay := &authRelay{}
+	server := httptest.NewServer(relay)
+	t.Cleanup(server.Close)
+	return relay, nostr.NormalizeURL("ws" + strings.TrimPrefix(server.URL, "http"))
+}
+
+func relayStatus(t *testing.T, c *NostrClient, url string) model.RelayStatus {
+	t.Helper()
+	for _, status := range c.health.snapshot() {
+		if status.URL == url {
+			return status
+		}
+	}
+	t.Fatalf("relay %s is not tracked", url)
+	return model.RelayStatus{}
+}
+
+func TestAuthRetriesPublishAndFetch(t *testing.T) {
+	relay, url := startAuthRelay(t)
+	privKey := nostr.GeneratePrivateKey()
+	c := newAuthTestClient(t, url, privKey)
+
+	evt := nostr.Event{Kind: 1111, Content: "members only"}
+	if err := c.signAndPublish(&evt); err != nil {
+		t.Fatalf("expected publish to succeed after auth, got %v", err)
+	}
+
+	relay.mu.Lock()
+	stored, authed := len(relay.events), relay.authed
+	relay.mu.Unlock()
+	if stored != 1 || len(authed) == 0 || authed[0] != c.PubKey() {
+		t.Fatalf("expected relay to authenticate us and keep the event, got %d events, authed %v", stored, authed)
+	}
+	if status := relayStatus(t, c, url); status.Auth != model.AuthOK || status.PublishOK != 1 {
+		t.Fatalf("unexpected relay status after publish: %+v", status)
+	}
+
+	// a new session has to answer a fresh challenge before its REQ is served
+	c = newAuthTestClient(t, url, privKey)
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	events := c.collect(ctx, nostr.Filter{Kinds: []int{1111}})
+	if len(events) != 1 || events[0].ID != evt.ID {
+		t.Fatalf("expected the fetch to be retried after auth, got %v", events)
+	}
+	if status := relayStatus(t, c, url); status.Auth != model.AuthOK {
+		t.Fatalf("expected relay to be authenticated after fetch, got %v", status.Auth)
+	}
+}
+
+func TestAuthWithoutKeyMarksRelay(t *testing.T) {
+	_, url := startAuthRelay(t)
+	c := newAuthTestClient(t, url, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	if events := c.collect(ctx, nostr.Filter{Kinds: []int{1111}}); len(events) != 0 {
+		t.Fatalf("expected no events without auth, got %v", events)
+	}
+
+	status := relayStatus(t, c, url)
+	if status.Auth != model.AuthRequired || !strings.Contains(status.LastError, ErrNoPrivateKey.Error()) {
+		t.Fatalf("expected relay to be marked as requiring auth, got %+v", status)
+	}
+}
+
+func TestAuthOnlyOnOurRelaysUntilAllowed(t *testing.T) {
+	relay, url := startAuthRelay(t)
+	privKey := nostr.GeneratePrivateKey()
+
+	seeded := newAuthTestClient(t, url, privKey)
+	evt := nostr.Event{Kind: 1111, Content: "members only"}
+	if err := seeded.signAndPublish(&evt); err != nil {
+		t.Fatalf("could not seed relay: %v", err)
+	}
+
+	// the relay is only someone else's outbox relay for this client
+	c := newAuthTestClient(t, "wss://configured.example.com", privKey)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if events := c.collectFrom(ctx, []string{url}, nostr.Filter{Kinds: []int{1111}}); len(events) != 0 {
+		t.Fatalf("expected no events from a relay we didn't authenticate to, got %v", events)
+	}
+	relay.mu.Lock()
+	authed := len(relay.authed)
+	relay.mu.Unlock()
+	if authed != 1 {
+		t.Fatalf("expected only the seeding client to authenticate, got %d", authed)
+	}
+	if status := relayStatus(t, c, url); status.Auth != model.AuthRequired {
+		t.Fatalf("expected relay to wait for the user, got %+v", status)
+	}
+
+	c.AllowRelayAuth(url)
+	if events := c.collectFrom(ctx, []string{url}, nostr.Filter{Kinds: []int{1111}}); len(events) != 1 {
+		t.Fatalf("expected the fetch to authenticate once allowed, got %v", events)
+	}
+	if status := relayStatus(t, c, url); status.Auth != model.AuthOK || !status.AuthAllowed {
+		t.Fatalf("expected relay to be authenticated, got %+v", status)
+	}
+}
+
+func TestPublishAuthErrorsUpdateState(t *testing.T) {
+	health := newRelayHealth(nil)
+	url := "wss://a.example.com"
+
+	health.setAuth(url, model.AuthPending, nil)
+	health.published(url, errors.New("failed to auth: msg: restricted: not a member"))
+	if got := health.snapshot()[0].Auth; got != model.AuthFailed {
+		t.Fatalf("expected rejected auth to be failed, got %v", got)
+	}
+
+	health.setAuth(url, model.AuthRequired, ErrNoPrivateKey)
+	health.published(url, errors.New("msg: auth-required: members only"))
+	if got := health.snapshot()[0].Auth; got != model.AuthRequired {
+		t.Fatalf("expected missing key to stay required, got %v", got)
+	}
+}
@@ -1,13 +1,19 @@
 package client
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 type cacheEntry[T any] struct {
 	value  T
 	expiry time.Time
 }
 
+// simpleCache is safe for concurrent use; tea commands and live subscriptions
+// read and write it from their own goroutines.
 type simpleCache[T any] struct {
+	mu    sync.Mutex
 	items map[string]cacheEntry[T]
 }
 
@@ -21,6 +27,9 @@ func (c *simpleCache[T]) get(key string) (T, bool) {
 		return zero, false
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	entry, ok := c.items[key]
 	if !ok {
 		var zero T
@@ -41,6 +50,8 @@ func (c *simpleCache[T]) set(key string, value T, expiry time.Time) {
 		return
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.items[key] = cacheEntry[T]{value: value, expiry: expiry}
 }
 
@@ -48,5 +59,8 @@ func (c *simpleCache[T]) clear() {
 	if c == nil {
 		return
 	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.items = make(map[string]cacheEntry[T])
 }
@@ -6,9 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"tuistr/config"
 	"tuistr/model"
@@ -22,47 +26,71 @@ import (
 var (
 	ErrNoRelays         = errors.New("no relays configured")
 	ErrNotFound         = errors.New("event not found")
-	ErrNoPrivateKey     = errors.New("no nostr private key configured")
-	ErrInvalidCommunity = errors.New("community must be a topic (t:...) for now")
+	ErrNoPrivateKey     = errors.New("no nostr secret key or bunker configured")
+	ErrInvalidCommunity = errors.New("community must be a NIP-73 id (t:, u: or g:)")
 	ErrInvalidThreadID  = errors.New("cannot reply: thread id is not a nostr event id (likely demo data)")
+	ErrOffline          = errors.New("cannot publish while offline")
 )
 
 type NostrClient struct {
-	pool        *nostr.SimplePool
-	relays      []string
-	timeout     time.Duration
-	limit       int
-	featured    []string
-	community   string
-	privKey     string
-	pubKey      string
-	postCache   *simpleCache[model.Posts]
-	threadCache *simpleCache[model.Comments]
+	pool         *nostr.SimplePool
+	relaysMu     sync.RWMutex
+	relays       []string
+	writeRelays  []string
+	health       *relayHealth
+	timeout      time.Duration
+	limit        int
+	featured     []string
+	community    string
+	signer       Signer
+	postCache    *simpleCache[model.Posts]
+	threadCache  *simpleCache[model.Comments]
+	profiles     *profileStore
+	nip05        *nip05Verifier
+	reactions    *reactionStore
+	deletions    *deletionSet
+	replyCounts  *simpleCache[int]
+	countSupport *simpleCache[bool]
+	relayLists   *simpleCache[relayList]
+	outbox       *outboxPool
+	store        *eventStore
+	offline      bool
 }
 
 func NewNostrClient(cfg config.Config) (*NostrClient, error) {
-	if len(cfg.Nostr.Relays) == 0 {
+	return newNostrClient(cfg, nil)
+}
+
+// SwitchAccount creates the client for another account's cfg. It shares c's event
+// store, so the store file is never open twice while the caller swaps clients.
+func (c *NostrClient) SwitchAccount(cfg config.Config) (*NostrClient, error) {
+	return newNostrClient(cfg, c.store)
+}
+
+func newNostrClient(cfg config.Config, shared *eventStore) (*NostrClient, error) {
+	if len(cfg.Nostr.ReadRelays()) == 0 {
 		return nil, ErrNoRelays
 	}
 
-	var (
-		privKey string
-		pubKey  string
-		err     error
-	)
-	if strings.TrimSpace(cfg.Nostr.SecretKey) != "" {
-		privKey, pubKey, err = parsePrivKey(cfg.Nostr.SecretKey)
+	var secretKey string
+	if strings.TrimSpace(cfg.Nostr.Bunker) == "" {
+		key, err := cfg.Nostr.LoadSecretKey()
 		if err != nil {
 			return nil, err
 		}
+		secretKey = key
 	}
 
-	ctx := context.Background()
-	pool := nostr.NewSimplePool(ctx)
+	signer, err := newSigner(cfg.Nostr.Bunker, secretKey)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, relay := range cfg.Nostr.Relays {
-		if _, err := pool.EnsureRelay(relay); err != nil {
-			slog.Warn("Could not connect to relay", "relay", relay, "error", err)
+	// The store is best effort: without it we only lose offline browsing
+	store := shared.retain()
+	if store == nil {
+		if store, err = openDefaultEventStore(); err != nil {
+			slog.Warn("Could not open event store", "error", err)
 		}
 	}
 
@@ -76,21 +104,86 @@ func NewNostrClient(cfg config.Config) (*NostrClient, error) {
 		limit = 50
 	}
 
-	return &NostrClient{
-		pool:        pool,
-		relays:      cfg.Nostr.Relays,
-		timeout:     timeout,
-		limit:       limit,
-		featured:    cfg.Communities.Featured,
-		privKey:     privKey,
-		pubKey:      pubKey,
-		postCache:   newSimpleCache[model.Posts](),
-		threadCache: newSimpleCache[model.Comments](),
-	}, nil
+	client := &NostrClient{
+		relays:       cfg.Nostr.ReadRelays(),
+		writeRelays:  cfg.Nostr.WriteRelays(),
+		health:       newRelayHealth(allRelays(cfg.Nostr)),
+		timeout:      timeout,
+		limit:        limit,
+		featured:     cfg.Communities.Featured,
+		signer:       signer,
+		postCache:    newSimpleCache[model.Posts](),
+		threadCache:  newSimpleCache[model.Comments](),
+		profiles:     newProfileStore(),
+		nip05:        newNip05Verifier(newHTTPNip05Resolver(nip05HTTPClient(timeout), nil)),
+		reactions:    newReactionStore(),
+		deletions:    newDeletionSet(),
+		replyCounts:  newSimpleCache[int](),
+		countSupport: newSimpleCache[bool](),
+		relayLists:   newSimpleCache[relayList](),
+		store:        store,
+		offline:      cfg.Nostr.Offline,
+	}
+
+	client.pool = client.newPool(context.Background())
+	client.outbox = newOutboxPool(maxOutboxRelays, client.isConfiguredRelay, client.closeRelay)
+
+	if !cfg.Nostr.Offline {
+		for _, relay := range allRelays(cfg.Nostr) {
+			if err := client.connectRelay(relay); err != nil {
+				slog.Warn("Could not connect to relay", "relay", relay, "error", err)
+			}
+		}
+	}
+
+	// connect to the remote signer early so the approval prompt shows up before
+	// the first publish
+	if bunker, ok := signer.(*bunkerSigner); ok && !cfg.Nostr.Offline {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), bunkerTimeout)
+			defer cancel()
+			if err := bunker.connect(ctx); err != nil {
+				slog.Warn("Could not connect to remote signer", "error", err)
+			}
+		}()
+	}
+
+	client.loadStoredProfiles()
+	return client, nil
+}
+
+func allRelays(cfg config.NostrConfig) []string {
+	var urls []string
+	for _, mode := range cfg.RelayModes() {
+		urls = append(urls, mode.URL)
+	}
+	return urls
+}
+
+func openDefaultEventStore() (*eventStore, error) {
+	cacheDir, err := utils.GetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(cacheDir, 0750); err != nil {
+		return nil, err
+	}
+
+	return openEventStore(filepath.Join(cacheDir, eventStoreFilename))
 }
 
 func (c *NostrClient) Close() {
 	c.pool.Close("shutdown")
+	if c.signer != nil {
+		c.signer.Close()
+	}
+	c.store.close()
+}
+
+// Offline reports whether the client only reads from the local event store.
+func (c *NostrClient) Offline() bool {
+	return c.offline
 }
 
 func (c *NostrClient) GetFeaturedPosts(until string) (model.Posts, error) {
@@ -103,50 +196,104 @@ func (c *NostrClient) GetCommunityPosts(community, until string) (model.Posts, e
 
 func (c *NostrClient) GetThread(post model.Post) (model.Comments, error) {
 	if cached, ok := c.threadCache.get(post.ThreadID); ok {
-		return cached, nil
+		return c.applyCommentNames(cached), nil
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
 
-	replyMap := make(map[string]nostr.Event)
+	relays, release := c.threadRelaysFor(ctx, post.PubKey)
+	defer release()
 
-	threadFilters := []nostr.Filter{
-		{Kinds: []int{1, 1111}, Tags: nostr.TagMap{"e": {post.ThreadID}}, Limit: c.limit},
-		{Kinds: []int{1, 1111}, Tags: nostr.TagMap{"E": {post.ThreadID}}, Limit: c.limit},
+	var events []nostr.Event
+	for _, f := range threadFilters(post.EventIDs(), c.limit) {
+		events = append(events, c.collectFrom(ctx, relays, f)...)
 	}
+	c.loadDeletions(threadAuthors(post, events), func(f nostr.Filter) []nostr.Event {
+		return c.collectFrom(ctx, relays, f)
+	})
 
-	for _, f := range threadFilters {
-		events := c.collect(ctx, f)
-		for _, evt := range events {
-			if evt.ID == post.ThreadID {
-				continue
-			}
-			replyMap[evt.ID] = evt
+	commentsModel := c.buildComments(post, events)
+	c.threadCache.set(post.ThreadID, commentsModel, commentsModel.Expiry)
+	return commentsModel, nil
+}
+
+// GetStoredThread returns the thread as far as the local event store knows it.
+// The result is marked stale unless we are offline, where the store is all we have.
+func (c *NostrClient) GetStoredThread(post model.Post) (model.Comments, bool) {
+	var events []nostr.Event
+	for _, f := range threadFilters(post.EventIDs(), c.limit) {
+		events = append(events, c.store.query(f)...)
+	}
+
+	if len(events) == 0 {
+		return model.Comments{}, false
+	}
+	c.loadDeletions(threadAuthors(post, events), c.store.query)
+
+	comments := c.buildComments(post, events)
+	comments.Stale = !c.offline
+	return comments, true
+}
+
+// threadFilters matches replies to any of ids, which are a post and the versions
+// it replaced.
+func threadFilters(ids []string, limit int) []nostr.Filter {
+	return []nostr.Filter{
+		{Kinds: []int{1, 1111}, Tags: nostr.TagMap{"e": ids}, Limit: limit},
+		{Kinds: []int{1, 1111}, Tags: nostr.TagMap{"E": ids}, Limit: limit},
+	}
+}
+
+// threadAuthors maps the thread root and its replies to their authors.
+func threadAuthors(post model.Post, events []nostr.Event) map[string]string {
+	authors := eventAuthors(events)
+	for _, id := range post.EventIDs() {
+		authors[id] = post.PubKey
+	}
+	return authors
+}
+
+func (c *NostrClient) buildComments(post model.Post, events []nostr.Event) model.Comments {
+	versions := post.EventIDs()
+	replyMap := make(map[string]nostr.Event)
+	for _, evt := range events {
+		if slices.Contains(versions, evt.ID) {
+			continue
 		}
+		replyMap[evt.ID] = evt
 	}
 
+	// replies to an earlier version are orphans here, which puts them under the root
 	thread := buildThread(post.ThreadID, replyMap)
 
 	comments := make([]model.Comment, 0, len(thread))
 	for _, evt := range thread {
-		comments = append(comments, c.eventToComment(evt.Event, evt.Depth))
+		comment := c.eventToComment(evt.Event, evt.Depth)
+		// keep the comment so its replies stay in place, but drop what it said
+		if c.deletions.has(comment.ID) {
+			comment.Deleted = true
+			comment.Text = ""
+		}
+		comments = append(comments, comment)
 	}
 
-	commentsModel := model.Comments{
+	return model.Comments{
 		PostID:        post.ID,
 		PostTitle:     post.PostTitle,
 		PostAuthor:    post.Author,
+		PostVerified:  post.Verified,
+		PostPubKey:    post.PubKey,
 		Community:     post.Community,
 		PostText:      post.Content,
 		PostUrl:       post.PostUrl,
-		PostTimestamp: utils.FriendlyTime(post.CreatedAt),
+		PostTimestamp: utils.FriendlyTime(post.Posted()),
+		PostScore:     post.Score,
+		PostDeleted:   c.deletions.has(post.ThreadID),
+		PostEdited:    post.Edited(),
 		Comments:      comments,
 		Expiry:        time.Now().Add(10 * time.Minute),
 	}
-
-	c.threadCache.set(post.ThreadID, commentsModel, commentsModel.Expiry)
-	return commentsModel, nil
 }
 
 func (c *NostrClient) GetPostByID(id string) (model.Post, error) {
@@ -168,29 +315,80 @@ func (c *NostrClient) GetPostByID(id string) (model.Post, error) {
 func (c *NostrClient) fetchPosts(communities []string, until string, isHome bool) (model.Posts, error) {
 	cacheKey := c.postsCacheKey(communities, until, isHome)
 	if cached, ok := c.postCache.get(cacheKey); ok {
-		return cached, nil
+		return c.applyPostNames(cached), nil
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	events := c.collect(ctx, c.postsFilter(communities, until))
+	c.loadDeletions(eventAuthors(events), func(f nostr.Filter) []nostr.Event {
+		return c.collect(ctx, f)
+	})
+
+	result := c.buildPosts(events, communities, isHome)
+	c.postCache.set(cacheKey, result, result.Expiry)
+	return result, nil
+}
+
+// GetStoredFeaturedPosts returns the first page of the featured feed from the
+// local event store.
+func (c *NostrClient) GetStoredFeaturedPosts() (model.Posts, bool) {
+	return c.storedPosts(c.featured, true)
+}
+
+// GetStoredCommunityPosts returns the first page of a community from the local
+// event store.
+func (c *NostrClient) GetStoredCommunityPosts(community string) (model.Posts, bool) {
+	return c.storedPosts([]string{community}, false)
+}
+
+func (c *NostrClient) storedPosts(communities []string, isHome bool) (model.Posts, bool) {
+	events := c.store.query(c.postsFilter(communities, ""))
+	if len(events) == 0 {
+		return model.Posts{}, false
+	}
+	c.loadDeletions(eventAuthors(events), c.store.query)
+
+	posts := c.buildPosts(events, communities, isHome)
+	posts.Stale = !c.offline
+	return posts, len(posts.Posts) > 0
+}
+
+func (c *NostrClient) postsFilter(communities []string, until string) nostr.Filter {
 	filter := nostr.Filter{
 		Kinds: []int{1111},
 		Limit: c.limit,
 	}
 
 	if len(communities) > 0 {
-		filter.Tags = nostr.TagMap{"I": communities}
+		// Posts are tagged with the normalized id, so look for that
+		normalized := make([]string, 0, len(communities))
+		for _, community := range communities {
+			normalized = append(normalized, utils.NormalizeCommunity(community))
+		}
+		filter.Tags = nostr.TagMap{"I": normalized}
 	}
 
 	if cursor := parseCursor(until); cursor != nil {
 		filter.Until = cursor
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
-	defer cancel()
-
-	events := c.collect(ctx, filter)
+	return filter
+}
 
+func (c *NostrClient) buildPosts(events []nostr.Event, communities []string, isHome bool) model.Posts {
+	var oldest nostr.Timestamp
+	superseded := supersededIDs(events)
 	dedup := make(map[string]nostr.Event)
 	for _, evt := range events {
+		if oldest == 0 || evt.CreatedAt < oldest {
+			oldest = evt.CreatedAt
+		}
+		// Replies share the community I tag; only roots belong in the feed
+		if !isTopLevel(evt) || c.deletions.has(evt.ID) || superseded[evt.ID] {
+			continue
+		}
 		dedup[evt.ID] = evt
 	}
 
@@ -208,9 +406,9 @@ func (c *NostrClient) fetchPosts(communities []string, until string, isHome bool
 		posts = append(posts, c.eventToPost(evt))
 	}
 
+	// Page on every fetched event so a window full of replies still advances
 	after := ""
-	if len(uniqueEvents) > 0 {
-		oldest := uniqueEvents[len(uniqueEvents)-1].CreatedAt
+	if oldest > 0 {
 		after = strconv.FormatInt(int64(oldest), 10)
 	}
 
@@ -223,7 +421,7 @@ func (c *NostrClient) fetchPosts(communities []string, until string, isHome bool
 		description = "Featured communities timeline"
 	}
 
-	result := model.Posts{
+	return model.Posts{
 		Description: description,
 		Community:   communityLabel,
 		IsHome:      isHome,
@@ -231,16 +429,14 @@ func (c *NostrClient) fetchPosts(communities []string, until string, isHome bool
 		After:       after,
 		Expiry:      time.Now().Add(30 * time.Minute),
 	}
-
-	c.postCache.set(cacheKey, result, result.Expiry)
-	return result, nil
 }
 
 func (c *NostrClient) eventToPost(evt nostr.Event) model.Post {
-	created := time.Unix(int64(evt.CreatedAt), 0)
-	title := evt.Content
-	if subject := evt.Tags.GetFirst([]string{"subject"}); subject != nil && len(*subject) > 1 && strings.TrimSpace((*subject)[1]) != "" {
-		title = (*subject)[1]
+	created, published := time.Unix(int64(evt.CreatedAt), 0), publishedAt(evt)
+	title, subject := evt.Content, ""
+	if tag := evt.Tags.GetFirst([]string{"subject"}); tag != nil && len(*tag) > 1 && strings.TrimSpace((*tag)[1]) != "" {
+		subject = strings.TrimSpace((*tag)[1])
+		title = subject
 	}
 
 	title = strings.TrimSpace(title)
@@ -256,8 +452,10 @@ func (c *NostrClient) eventToPost(evt nostr.Event) model.Post {
 		community = utils.NormalizeCommunity(community)
 	}
 
+	author := c.profileFor(evt.PubKey)
+
 	postUrl := fmt.Sprintf("https://nostr.eu/%s", evt.ID)
-	if nevent, err := nip19.EncodeEvent(evt.ID, c.relays, evt.PubKey); err == nil {
+	if nevent, err := nip19.EncodeEvent(evt.ID, c.activeRelays(), evt.PubKey); err == nil {
 		postUrl = fmt.Sprintf("https://nostr.eu/%s", nevent)
 	}
 
@@ -265,90 +463,270 @@ func (c *NostrClient) eventToPost(evt nostr.Event) model.Post {
 		ID:           evt.ID,
 		PostTitle:    title,
 		Content:      evt.Content,
-		Author:       utils.ShortenPubKey(evt.PubKey),
+		Author:       author.Name,
+		Verified:     author.Verified,
 		PubKey:       evt.PubKey,
+		Kind:         evt.Kind,
 		Community:    community,
-		FriendlyDate: utils.FriendlyTime(created),
+		FriendlyDate: utils.FriendlyTime(published),
 		CreatedAt:    created,
+		PublishedAt:  published,
 		PostUrl:      postUrl,
 		ThreadID:     evt.ID,
+		Subject:      subject,
+		Revises:      c.ownRevisions(evt),
 	}
 }
 
 func (c *NostrClient) eventToComment(evt nostr.Event, depth int) model.Comment {
 	created := time.Unix(int64(evt.CreatedAt), 0)
+	author := c.profileFor(evt.PubKey)
 	return model.Comment{
 		ID:        evt.ID,
-		Author:    utils.ShortenPubKey(evt.PubKey),
+		Author:    author.Name,
+		Verified:  author.Verified,
+		PubKey:    evt.PubKey,
+		Kind:      evt.Kind,
+		ParentID:  immediateParentID(evt.Tags, ""),
 		Text:      evt.Content,
 		Timestamp: utils.FriendlyTime(created),
 		Depth:     depth,
 	}
 }
 
-func (c *NostrClient) PublishPost(community, content string) (model.Post, error) {
+// PublishPost starts a thread in community. A non-empty title is sent as the
+// subject tag, otherwise readers title the post with its first line.
+func (c *NostrClient) PublishPost(community, title, content string) (model.Post, error) {
+	evt, err := c.postEvent(community, title, content)
+	if err != nil {
+		return model.Post{}, err
+	}
+
+	if err := c.signAndPublish(&evt); err != nil {
+		return model.Post{}, err
+	}
+
+	c.postCache.clear()
+	c.threadCache.clear()
+
+	return c.eventToPost(evt), nil
+}
+
+// PublishReply replies to the thread root, or to parent when it is set (non-empty ID).
+func (c *NostrClient) PublishReply(post model.Post, parent model.Comment, content string) (model.Comment, error) {
+	evt, err := c.replyEvent(post, parent, content)
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	if err := c.signAndPublish(&evt, post.PubKey, parent.PubKey); err != nil {
+		return model.Comment{}, err
+	}
+
+	c.threadCache.clear()
+	c.postCache.clear()
+	if count, ok := c.replyCounts.get(post.ThreadID); ok {
+		c.replyCounts.set(post.ThreadID, count+1, time.Now().Add(replyCountTTL))
+	}
+
+	depth := 0
+	if parent.ID != "" {
+		depth = parent.Depth + 1
+	}
+
+	return c.eventToComment(evt, depth), nil
+}
+
+func (c *NostrClient) postEvent(community, title, content string) (nostr.Event, error) {
 	if strings.TrimSpace(content) == "" {
-		return model.Post{}, errors.New("content is required")
+		return nostr.Event{}, errors.New("content is required")
 	}
-	if c.privKey == "" {
-		return model.Post{}, ErrNoPrivateKey
+	if c.signer == nil {
+		return nostr.Event{}, ErrNoPrivateKey
 	}
 
-	normalized := utils.NormalizeCommunity(community)
-	if !utils.ValidateTopic(normalized) {
-		return model.Post{}, ErrInvalidCommunity
+	normalized, err := utils.ParseCommunity(community)
+	if err != nil {
+		return nostr.Event{}, ErrInvalidCommunity
 	}
 
-	evt := nostr.Event{
+	return nostr.Event{
 		Kind:    1111,
-		Tags:    nostr.Tags{{"I", normalized}},
+		Tags:    postTags(normalized, title),
 		Content: strings.TrimSpace(content),
+	}, nil
+}
+
+func (c *NostrClient) replyEvent(post model.Post, parent model.Comment, content string) (nostr.Event, error) {
+	if strings.TrimSpace(content) == "" {
+		return nostr.Event{}, errors.New("content is required")
+	}
+	if c.signer == nil {
+		return nostr.Event{}, ErrNoPrivateKey
+	}
+	if !isValidEventID(post.ThreadID) {
+		return nostr.Event{}, ErrInvalidThreadID
+	}
+	if parent.ID != "" && !isValidEventID(parent.ID) {
+		return nostr.Event{}, ErrInvalidThreadID
 	}
 
-	if err := c.signAndPublish(&evt); err != nil {
-		return model.Post{}, err
+	return nostr.Event{
+		Kind:    1111,
+		Tags:    replyTags(post, parent),
+		Content: strings.TrimSpace(content),
+	}, nil
+}
+
+// PreviewPost shows what PublishPost would send, without signing or sending it.
+func (c *NostrClient) PreviewPost(community, title, content string) (model.PublishPreview, error) {
+	evt, err := c.postEvent(community, title, content)
+	if err != nil {
+		return model.PublishPreview{}, err
 	}
+	return c.postPreview(evt), nil
+}
 
-	c.postCache.clear()
-	c.threadCache.clear()
+// postPreview shows an unsigned top level post as the thread header would.
+func (c *NostrClient) postPreview(evt nostr.Event) model.PublishPreview {
+	evt.PubKey, evt.CreatedAt = c.PubKey(), nostr.Now()
+	post := c.eventToPost(evt)
+	thread := model.Comments{
+		PostTitle:     post.PostTitle,
+		PostAuthor:    post.Author,
+		PostVerified:  post.Verified,
+		PostPubKey:    post.PubKey,
+		Community:     post.Community,
+		PostText:      post.Content,
+		PostTimestamp: post.FriendlyDate,
+		PostEdited:    post.Edited(),
+	}
 
-	return c.eventToPost(evt), nil
+	return c.publishPreview(evt, thread)
 }
 
-func (c *NostrClient) PublishReply(post model.Post, content string) (model.Comment, error) {
-	if strings.TrimSpace(content) == "" {
-		return model.Comment{}, errors.New("content is required")
+// PreviewReply shows what PublishReply would send: the reply under its parent,
+// as the thread view would place it.
+func (c *NostrClient) PreviewReply(post model.Post, parent model.Comment, content string) (model.PublishPreview, error) {
+	evt, err := c.replyEvent(post, parent, content)
+	if err != nil {
+		return model.PublishPreview{}, err
 	}
-	if c.privKey == "" {
-		return model.Comment{}, ErrNoPrivateKey
+
+	evt.PubKey, evt.CreatedAt = c.PubKey(), nostr.Now()
+	thread := model.Comments{
+		PostTitle:     post.PostTitle,
+		PostAuthor:    post.Author,
+		PostVerified:  post.Verified,
+		PostPubKey:    post.PubKey,
+		Community:     post.Community,
+		PostTimestamp: post.FriendlyDate,
+		PostScore:     post.Score,
 	}
-	if !isValidEventID(post.ThreadID) {
-		return model.Comment{}, ErrInvalidThreadID
+
+	if parent.ID != "" {
+		parent.Depth = 0
+		thread.Comments = []model.Comment{parent, c.eventToComment(evt, 1)}
+	} else {
+		thread.Comments = []model.Comment{c.eventToComment(evt, 0)}
 	}
 
-	tags := nostr.Tags{
-		{"e", post.ThreadID},
-		{"E", post.ThreadID},
+	return c.publishPreview(evt, thread, post.PubKey, parent.PubKey), nil
+}
+
+func (c *NostrClient) publishPreview(evt nostr.Event, thread model.Comments, recipients ...string) model.PublishPreview {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	tags := make([][]string, 0, len(evt.Tags))
+	for _, tag := range evt.Tags {
+		tags = append(tags, []string(tag))
 	}
 
-	if utils.ValidateTopic(post.Community) {
-		tags = append(tags, nostr.Tag{"I", utils.NormalizeCommunity(post.Community)})
+	relays, release := c.publishRelays(ctx, recipients)
+	release()
+
+	return model.PublishPreview{
+		Thread: thread,
+		Tags:   tags,
+		Relays: relays,
 	}
+}
 
-	evt := nostr.Event{
-		Kind:    1,
-		Tags:    tags,
-		Content: strings.TrimSpace(content),
+// communityTags scopes a top level post to its community: the NIP-73 identifier is
+// both the root (I/K) and the immediate parent (i/k).
+func communityTags(community string) nostr.Tags {
+	kind := utils.CommunityKind(community)
+	return nostr.Tags{
+		{"I", community},
+		{"K", kind},
+		{"i", community},
+		{"k", kind},
 	}
+}
 
-	if err := c.signAndPublish(&evt); err != nil {
-		return model.Comment{}, err
+func postTags(community, title string) nostr.Tags {
+	tags := communityTags(community)
+	if title = firstLine(title); title != "" {
+		tags = append(tags, nostr.Tag{"subject", title})
 	}
+	return tags
+}
 
-	c.threadCache.clear()
-	c.postCache.clear()
+// replyTags builds the NIP-22 tags for a reply: the community root scope (I/K) and
+// the immediate parent (e/k/p). The thread root doubles as the parent when no
+// comment is selected; otherwise it follows the parent as a root-marked e tag, so
+// nested replies are still found by the thread's id. Without a known community the
+// thread root event is the root scope (E/K/P).
+func replyTags(post model.Post, parent model.Comment) nostr.Tags {
+	rootKind := post.Kind
+	if rootKind == 0 {
+		rootKind = 1111
+	}
+
+	parentID, parentKind, parentPubKey := post.ThreadID, rootKind, post.PubKey
+	if parent.ID != "" {
+		parentID, parentKind, parentPubKey = parent.ID, parent.Kind, parent.PubKey
+		if parentKind == 0 {
+			parentKind = 1111
+		}
+	}
+
+	var tags nostr.Tags
+	if community, err := utils.ParseCommunity(post.Community); err == nil {
+		tags = append(tags, nostr.Tag{"I", community}, nostr.Tag{"K", utils.CommunityKind(community)})
+	} else {
+		tags = append(tags, eventTag("E", post.ThreadID, post.PubKey), nostr.Tag{"K", strconv.Itoa(rootKind)})
+		if post.PubKey != "" {
+			tags = append(tags, nostr.Tag{"P", post.PubKey})
+		}
+	}
 
-	return c.eventToComment(evt, 0), nil
+	tags = append(tags,
+		eventTag("e", parentID, parentPubKey),
+		nostr.Tag{"k", strconv.Itoa(parentKind)},
+	)
+	if parentPubKey != "" {
+		tags = append(tags, nostr.Tag{"p", parentPubKey})
+	}
+	if parentID != post.ThreadID {
+		tags = append(tags, nostr.Tag{"e", post.ThreadID, "", "root", post.PubKey})
+	}
+
+	return tags
+}
+
+// isTopLevel reports whether a kind 1111 event is a community post rather than a
+// comment inside a thread.
+func isTopLevel(evt nostr.Event) bool {
+	return evt.Tags.GetFirst([]string{"e"}) == nil && evt.Tags.GetFirst([]string{"E"}) == nil
+}
+
+func eventTag(name, id, pubKey string) nostr.Tag {
+	if pubKey == "" {
+		return nostr.Tag{name, id}
+	}
+	return nostr.Tag{name, id, "", pubKey}
 }
 
 // EncodeNevent returns a nip19 nevent for the given post.
@@ -357,31 +735,61 @@ func (c *NostrClient) EncodeNevent(post model.Post) (string, error) {
 		return "", ErrInvalidThreadID
 	}
 	// Prefer relays we already queried so links resolve predictably.
-	return nip19.EncodeEvent(post.ID, c.relays, post.PubKey)
+	return nip19.EncodeEvent(post.ID, c.activeRelays(), post.PubKey)
+}
+
+// SignerLocked reports whether the secret key is still waiting for its passphrase.
+func (c *NostrClient) SignerLocked() bool {
+	encrypted, ok := c.signer.(*encryptedSigner)
+	return ok && encrypted.Locked()
+}
+
+// UnlockSigner decrypts an ncryptsec secret key for the rest of the session.
+func (c *NostrClient) UnlockSigner(passphrase string) error {
+	encrypted, ok := c.signer.(*encryptedSigner)
+	if !ok {
+		return nil
+	}
+	return encrypted.Unlock(passphrase)
+}
+
+// PubKey returns the user's public key, or "" without a signer (or before a remote
+// signer has connected or an encrypted key is unlocked).
+func (c *NostrClient) PubKey() string {
+	if c.signer == nil {
+		return ""
+	}
+	return c.signer.PublicKey()
 }
 
-func (c *NostrClient) signAndPublish(evt *nostr.Event) error {
-	if c.privKey == "" {
+// signAndPublish sends evt to our write relays and to the read relays of recipients.
+func (c *NostrClient) signAndPublish(evt *nostr.Event, recipients ...string) error {
+	if c.signer == nil {
 		return ErrNoPrivateKey
 	}
+	if c.offline {
+		return ErrOffline
+	}
 
 	evt.CreatedAt = nostr.Now()
-	evt.PubKey = c.pubKey
-
-	if err := evt.Sign(c.privKey); err != nil {
+	if err := c.signer.SignEvent(context.Background(), evt); err != nil {
 		return err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 	defer cancel()
 
-	results := c.pool.PublishMany(ctx, c.relays, *evt)
+	relays, release := c.publishRelays(ctx, recipients)
+	defer release()
+
+	results := c.pool.PublishMany(ctx, relays, *evt)
 	var (
 		success    bool
 		errorsSeen []string
 	)
 
 	for res := range results {
+		c.health.published(res.RelayURL, res.Error)
 		if res.Error == nil {
 			success = true
 			continue
@@ -390,6 +798,7 @@ func (c *NostrClient) signAndPublish(evt *nostr.Event) error {
 	}
 
 	if success {
+		c.store.save(*evt)
 		return nil
 	}
 
@@ -479,15 +888,56 @@ func parsePrivKey(secret string) (string, string, error) {
 	return secret, pubKey, nil
 }
 
+// collect fetches events matching filter from the configured relays and keeps a
+// copy in the event store. Offline, the store answers instead.
 func (c *NostrClient) collect(ctx context.Context, filter nostr.Filter) []nostr.Event {
-	ch := c.pool.FetchMany(ctx, c.relays, filter)
-	var events []nostr.Event
-	for ev := range ch {
-		if ev.Event == nil {
-			continue
-		}
-		events = append(events, *ev.Event)
+	return c.collectFrom(ctx, c.activeRelays(), filter)
+}
+
+func (c *NostrClient) collectFrom(ctx context.Context, relays []string, filter nostr.Filter) []nostr.Event {
+	if c.offline {
+		return c.store.query(filter)
 	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		events []nostr.Event
+	)
+	seen := make(map[string]bool)
+
+	// One request per relay, so each relay's round trip can be timed
+	for _, url := range relays {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+
+			connected := c.relayConnected(url)
+			start := time.Now()
+			for ev := range c.pool.FetchMany(ctx, []string{url}, filter) {
+				if ev.Event == nil {
+					continue
+				}
+				c.health.received(ev.Relay.URL)
+
+				mu.Lock()
+				if !seen[ev.ID] {
+					seen[ev.ID] = true
+					events = append(events, *ev.Event)
+				}
+				mu.Unlock()
+			}
+
+			// The fetch ends when the relay answers with EOSE or CLOSED. Skip
+			// timeouts and fetches that had to dial first.
+			if connected && ctx.Err() == nil {
+				c.health.responded(url, time.Since(start))
+			}
+		}(url)
+	}
+	wg.Wait()
+
+	c.store.save(events...)
 	return events
 }
 
@@ -504,22 +954,28 @@ type threadNode struct {
 	Depth int
 }
 
+// immediateParentID returns the id of the event a reply answers, or fallback when
+// the tags don't point at one.
+func immediateParentID(tags nostr.Tags, fallback string) string {
+	if ptr := nip22.GetImmediateParent(tags); ptr != nil {
+		switch p := ptr.(type) {
+		case nostr.EventPointer:
+			return p.ID
+		case nostr.EntityPointer:
+			return p.AsTagReference()
+		case nostr.Pointer:
+			if val := p.AsTagReference(); val != "" {
+				return val
+			}
+		}
+	}
+	return fallback
+}
+
 func buildThread(rootID string, events map[string]nostr.Event) []threadNode {
 	children := make(map[string][]nostr.Event)
 	for _, evt := range events {
-		parentID := rootID
-		if ptr := nip22.GetImmediateParent(evt.Tags); ptr != nil {
-			switch p := ptr.(type) {
-			case nostr.EventPointer:
-				parentID = p.ID
-			case nostr.EntityPointer:
-				parentID = p.AsTagReference()
-			case nostr.Pointer:
-				if val := p.AsTagReference(); val != "" {
-					parentID = val
-				}
-			}
-		}
+		parentID := immediateParentID(evt.Tags, rootID)
 
 		// orphaned replies go back to root
 		if _, ok := events[parentID]; !ok && parentID != rootID {
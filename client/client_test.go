@@ -1,7 +1,12 @@
 package client
 
 import (
+	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"tuistr/config"
+	"tuistr/model"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip19"
@@ -48,3 +53,171 @@ func TestParsePrivKeyNsec(t *testing.T) {
 		t.Fatalf("expected pub %s, got %s", wantPub, pub)
 	}
 }
+
+func TestReplyTagsTargetSelectedComment(t *testing.T) {
+	post := model.Post{ThreadID: strings.Repeat("a", 64), PubKey: "rootpk", Kind: 1111}
+	parent := model.Comment{ID: strings.Repeat("b", 64), PubKey: "parentpk", Kind: 1111}
+
+	tags := replyTags(post, parent)
+
+	if root := tags.GetFirst([]string{"E"}); root == nil || (*root)[1] != post.ThreadID {
+		t.Fatalf("expected root E tag for thread, got %v", tags)
+	}
+	if p := tags.GetFirst([]string{"P"}); p == nil || (*p)[1] != "rootpk" {
+		t.Fatalf("expected root P tag, got %v", tags)
+	}
+	if e := tags.GetFirst([]string{"e"}); e == nil || (*e)[1] != parent.ID {
+		t.Fatalf("expected parent e tag, got %v", tags)
+	}
+	if p := tags.GetFirst([]string{"p"}); p == nil || (*p)[1] != "parentpk" {
+		t.Fatalf("expected parent p tag, got %v", tags)
+	}
+}
+
+func TestReplyTagsDefaultToRoot(t *testing.T) {
+	post := model.Post{ThreadID: strings.Repeat("a", 64), PubKey: "rootpk"}
+
+	tags := replyTags(post, model.Comment{})
+
+	if e := tags.GetFirst([]string{"e"}); e == nil || (*e)[1] != post.ThreadID {
+		t.Fatalf("expected parent e tag to point at root, got %v", tags)
+	}
+	if k := tags.GetFirst([]string{"k"}); k == nil || (*k)[1] != "1111" {
+		t.Fatalf("expected parent kind 1111, got %v", tags)
+	}
+}
+
+func TestBuildThreadNestsReplies(t *testing.T) {
+	rootID := strings.Repeat("a", 64)
+	post := model.Post{ThreadID: rootID, PubKey: "rootpk"}
+
+	first := nostr.Event{ID: strings.Repeat("b", 64), CreatedAt: 1, Tags: replyTags(post, model.Comment{})}
+	nested := nostr.Event{ID: strings.Repeat("c", 64), CreatedAt: 2, Tags: replyTags(post, model.Comment{ID: first.ID})}
+
+	thread := buildThread(rootID, map[string]nostr.Event{first.ID: first, nested.ID: nested})
+
+	if len(thread) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(thread))
+	}
+	if thread[0].Event.ID != first.ID || thread[0].Depth != 0 {
+		t.Fatalf("expected first reply at depth 0, got %+v", thread[0])
+	}
+	if thread[1].Event.ID != nested.ID || thread[1].Depth != 1 {
+		t.Fatalf("expected nested reply at depth 1, got %+v", thread[1])
+	}
+}
+
+func TestReplyTagsCarryCommunityScope(t *testing.T) {
+	post := model.Post{ThreadID: strings.Repeat("a", 64), PubKey: "rootpk", Community: "t:nostr"}
+
+	tags := replyTags(post, model.Comment{})
+
+	if i := tags.GetFirst([]string{"I"}); i == nil || (*i)[1] != "t:nostr" {
+		t.Fatalf("expected root I tag for community, got %v", tags)
+	}
+	if k := tags.GetFirst([]string{"K"}); k == nil || (*k)[1] != "#" {
+		t.Fatalf("expected root K tag for topic, got %v", tags)
+	}
+	if e := tags.GetFirst([]string{"E"}); e != nil {
+		t.Fatalf("expected the community to be the only root scope, got %v", tags)
+	}
+
+	// a nested reply still names the thread, after its parent
+	parent := model.Comment{ID: strings.Repeat("b", 64), PubKey: "parentpk", Kind: 1111}
+	nested := replyTags(post, parent)
+	if got := immediateParentID(nested, ""); got != parent.ID {
+		t.Fatalf("expected the comment as parent, got %s from %v", got, nested)
+	}
+	if last := nested[len(nested)-1]; last[0] != "e" || last[1] != post.ThreadID || last[3] != "root" {
+		t.Fatalf("expected a root-marked e tag for the thread, got %v", nested)
+	}
+
+	reply := nostr.Event{Kind: 1111, Tags: tags}
+	if isTopLevel(reply) {
+		t.Fatalf("expected reply not to be treated as a top level post")
+	}
+	if !isTopLevel(nostr.Event{Kind: 1111, Tags: communityTags("t:nostr")}) {
+		t.Fatalf("expected community post to be top level")
+	}
+}
+
+func TestCommunityTagsKindPerIdentifier(t *testing.T) {
+	cases := map[string]string{
+		"t:nostr":                "#",
+		"u:wss://relay.damus.io": "web",
+		"g:dr5regw3pg":           "geo",
+	}
+
+	for community, kind := range cases {
+		tags := communityTags(community)
+		for _, name := range []string{"I", "i"} {
+			if tag := tags.GetFirst([]string{name}); tag == nil || (*tag)[1] != community {
+				t.Fatalf("%s: expected %s tag %s, got %v", community, name, community, tags)
+			}
+		}
+		for _, name := range []string{"K", "k"} {
+			if tag := tags.GetFirst([]string{name}); tag == nil || (*tag)[1] != kind {
+				t.Fatalf("%s: expected %s tag %s, got %v", community, name, kind, tags)
+			}
+		}
+	}
+}
+
+func TestPostTagsSubject(t *testing.T) {
+	tags := postTags("t:nostr", "  Hello world\nsecond line")
+	if subject := tags.GetFirst([]string{"subject"}); subject == nil || (*subject)[1] != "Hello world" {
+		t.Fatalf("expected one-line subject tag, got %v", tags)
+	}
+	if tags.GetFirst([]string{"I"}) == nil {
+		t.Fatalf("expected community tags to be kept, got %v", tags)
+	}
+
+	if untitled := postTags("t:nostr", " "); untitled.GetFirst([]string{"subject"}) != nil {
+		t.Fatalf("expected no subject tag without a title, got %v", untitled)
+	}
+}
+
+func TestPreviewPostDoesNotSign(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.NewConfig()
+	cfg.Nostr.Relays = []string{"wss://relay.example.com"}
+	cfg.Nostr.SecretKey = nostr.GeneratePrivateKey()
+	cfg.Nostr.Offline = true
+
+	c, err := NewNostrClient(cfg)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer c.Close()
+
+	preview, err := c.PreviewPost("t:nostr", "Hello", "first line\nbody")
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+
+	if preview.Thread.PostTitle != "Hello" || preview.Thread.PostText != "first line\nbody" || preview.Thread.Community != "t:nostr" {
+		t.Fatalf("expected the post as the thread view shows it, got %+v", preview.Thread)
+	}
+	if !slices.ContainsFunc(preview.Tags, func(tag []string) bool { return tag[0] == "subject" && tag[1] == "Hello" }) {
+		t.Fatalf("expected subject tag in preview, got %v", preview.Tags)
+	}
+	if !slices.Equal(preview.Relays, []string{"wss://relay.example.com"}) {
+		t.Fatalf("expected write relays, got %v", preview.Relays)
+	}
+
+	if _, err := c.PreviewPost("bad community!", "", "body"); !errors.Is(err, ErrInvalidCommunity) {
+		t.Fatalf("expected invalid community error, got %v", err)
+	}
+}
+
+func TestPostsFilterNormalizesCommunities(t *testing.T) {
+	c := &NostrClient{limit: 10}
+
+	filter := c.postsFilter([]string{"t:Nostr", " u:https://Example.com:443/path#top"}, "")
+
+	want := []string{"t:nostr", "u:https://example.com/path"}
+	if got := filter.Tags["I"]; !slices.Equal(got, want) {
+		t.Fatalf("expected normalized I tags %v, got %v", want, got)
+	}
+}
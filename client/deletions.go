@@ -0,0 +1,101 @@
+package client
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const deletionBatchSize = 100
+
+var ErrNotAuthor = errors.New("only your own posts and replies can be edited or deleted")
+
+// deletionSet remembers events whose authors asked for them to be deleted (NIP-09).
+type deletionSet struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+func newDeletionSet() *deletionSet {
+	return &deletionSet{ids: make(map[string]bool)}
+}
+
+func (s *deletionSet) add(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[id] = true
+}
+
+func (s *deletionSet) has(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ids[id]
+}
+
+// record honors a kind 5 event for the targets it names, but only where the
+// request comes from the target's own author. authors maps event ids to pubkeys.
+func (s *deletionSet) record(deletion nostr.Event, authors map[string]string) {
+	if deletion.Kind != 5 {
+		return
+	}
+	for _, tag := range deletion.Tags {
+		if len(tag) >= 2 && tag[0] == "e" && authors[tag[1]] == deletion.PubKey {
+			s.add(tag[1])
+		}
+	}
+}
+
+// loadDeletions asks query for deletion requests aimed at the given events and
+// records the valid ones.
+func (c *NostrClient) loadDeletions(authors map[string]string, query func(nostr.Filter) []nostr.Event) {
+	ids := make([]string, 0, len(authors))
+	for id := range authors {
+		if isValidEventID(id) {
+			ids = append(ids, id)
+		}
+	}
+
+	for start := 0; start < len(ids); start += deletionBatchSize {
+		batch := ids[start:min(start+deletionBatchSize, len(ids))]
+		for _, deletion := range query(nostr.Filter{Kinds: []int{5}, Tags: nostr.TagMap{"e": batch}}) {
+			c.deletions.record(deletion, authors)
+		}
+	}
+}
+
+func eventAuthors(events []nostr.Event) map[string]string {
+	authors := make(map[string]string, len(events))
+	for _, evt := range events {
+		authors[evt.ID] = evt.PubKey
+	}
+	return authors
+}
+
+// DeleteEvent publishes a NIP-09 deletion request for one of the user's own
+// events. Relays and other clients may still keep a copy.
+func (c *NostrClient) DeleteEvent(id, pubKey string, kind int) error {
+	if c.signer == nil {
+		return ErrNoPrivateKey
+	}
+	if !isValidEventID(id) {
+		return ErrNotFound
+	}
+	if pubKey == "" || pubKey != c.PubKey() {
+		return ErrNotAuthor
+	}
+
+	evt := nostr.Event{
+		Kind: 5,
+		Tags: nostr.Tags{{"e", id}, {"k", strconv.Itoa(kind)}},
+	}
+	if err := c.signAndPublish(&evt); err != nil {
+		return err
+	}
+
+	c.deletions.add(id)
+	c.postCache.clear()
+	c.threadCache.clear()
+	return nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"tuistr/config"
+	"tuistr/model"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func signedEvent(t *testing.T, privKey string, evt nostr.Event) nostr.Event {
+	t.Helper()
+	evt.CreatedAt = nostr.Now()
+	if err := evt.Sign(privKey); err != nil {
+		t.Fatalf("could not sign test event: %v", err)
+	}
+	return evt
+}
+
+func deletionOf(t *testing.T, privKey string, target nostr.Event) nostr.Event {
+	return signedEvent(t, privKey, nostr.Event{Kind: 5, Tags: nostr.Tags{{"e", target.ID}}})
+}
+
+func TestDeletionsOnlyCountFromTheAuthor(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.NewConfig()
+	cfg.Nostr.Offline = true
+	c, err := NewNostrClient(cfg)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer c.Close()
+
+	author, stranger := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	kept := signedEvent(t, author, nostr.Event{Kind: 1111, Tags: communityTags("t:nostr"), Content: "keep me"})
+	deleted := signedEvent(t, author, nostr.Event{Kind: 1111, Tags: communityTags("t:nostr"), Content: "delete me"})
+	reply := signedEvent(t, author, nostr.Event{Kind: 1111, Tags: replyTags(c.eventToPost(kept), model.Comment{}), Content: "oops"})
+
+	c.store.save(kept, deleted, reply,
+		deletionOf(t, author, deleted),
+		deletionOf(t, author, reply),
+		// nobody but the author can delete an event
+		deletionOf(t, stranger, kept))
+
+	posts, err := c.GetCommunityPosts("t:nostr", "")
+	if err != nil {
+		t.Fatalf("could not load posts: %v", err)
+	}
+	if len(posts.Posts) != 1 || posts.Posts[0].ID != kept.ID {
+		t.Fatalf("expected only the kept post, got %+v", posts.Posts)
+	}
+
+	thread, err := c.GetThread(posts.Posts[0])
+	if err != nil {
+		t.Fatalf("could not load thread: %v", err)
+	}
+	if thread.PostDeleted {
+		t.Fatalf("expected a stranger's deletion request to be ignored")
+	}
+	if len(thread.Comments) != 1 || !thread.Comments[0].Deleted || thread.Comments[0].Text != "" {
+		t.Fatalf("expected the reply to be marked deleted, got %+v", thread.Comments)
+	}
+}
+
+func TestDeleteEventRefusesOtherAuthors(t *testing.T) {
+	c := &NostrClient{}
+	signer, err := newLocalSigner(nostr.GeneratePrivateKey())
+	if err != nil {
+		t.Fatalf("invalid test key: %v", err)
+	}
+	c.signer = signer
+
+	other, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	id := "1111111111111111111111111111111111111111111111111111111111111111"
+	if err := c.DeleteEvent(id, other, 1111); !errors.Is(err, ErrNotAuthor) {
+		t.Fatalf("expected ErrNotAuthor, got %v", err)
+	}
+}
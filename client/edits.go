@@ -0,0 +1,142 @@
+package client
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+	"tuistr/model"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// editTag names the versions a post revises. Clients that don't know it still
+// see the revision as a regular post; an e tag would make it a reply instead.
+const editTag = "revises"
+
+// publishedAtTag carries the first version's created_at on a revision (as in
+// NIP-23), so an edit doesn't bump the post to the top of the feed.
+const publishedAtTag = "published_at"
+
+// editTags points a revision at the post it replaces and everything that post
+// replaced in turn, oldest first, so replies to any version stay in the thread.
+func editTags(original model.Post) nostr.Tags {
+	tags := make(nostr.Tags, 0, len(original.Revises)+1)
+	for _, id := range original.Revises {
+		tags = append(tags, nostr.Tag{editTag, id})
+	}
+	return append(tags, nostr.Tag{editTag, original.ID})
+}
+
+// revisedIDs returns the versions evt says it replaces.
+func revisedIDs(evt nostr.Event) []string {
+	var ids []string
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 && tag[0] == editTag && isValidEventID(tag[1]) {
+			ids = append(ids, tag[1])
+		}
+	}
+	return ids
+}
+
+// ownRevisions returns the versions evt replaces that its own author wrote, so
+// a stranger's post can't claim another post's replies, reactions or deletions.
+// Versions missing from the event store are dropped along with foreign ones.
+func (c *NostrClient) ownRevisions(evt nostr.Event) []string {
+	claimed := revisedIDs(evt)
+	if len(claimed) == 0 {
+		return nil
+	}
+
+	authors := eventAuthors(c.store.query(nostr.Filter{IDs: claimed}))
+	var ids []string
+	for _, id := range claimed {
+		if authors[id] == evt.PubKey {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// publishedAt returns when the first version of a revision was posted, or its
+// own created_at. It is never later than the revision itself.
+func publishedAt(evt nostr.Event) time.Time {
+	created := time.Unix(int64(evt.CreatedAt), 0)
+	if len(revisedIDs(evt)) == 0 {
+		return created
+	}
+
+	tag := evt.Tags.GetFirst([]string{publishedAtTag})
+	if tag == nil || len(*tag) < 2 {
+		return created
+	}
+	ts, err := strconv.ParseInt((*tag)[1], 10, 64)
+	if err != nil || ts <= 0 || ts > int64(evt.CreatedAt) {
+		return created
+	}
+	return time.Unix(ts, 0)
+}
+
+// supersededIDs returns the events replaced by a revision from the same author.
+func supersededIDs(events []nostr.Event) map[string]bool {
+	authors := eventAuthors(events)
+	superseded := make(map[string]bool)
+	for _, evt := range events {
+		for _, id := range revisedIDs(evt) {
+			if authors[id] == evt.PubKey {
+				superseded[id] = true
+			}
+		}
+	}
+	return superseded
+}
+
+func (c *NostrClient) editEvent(original model.Post, title, content string) (nostr.Event, error) {
+	if !isValidEventID(original.ID) {
+		return nostr.Event{}, ErrNotFound
+	}
+	if original.PubKey == "" || original.PubKey != c.PubKey() {
+		return nostr.Event{}, ErrNotAuthor
+	}
+
+	evt, err := c.postEvent(original.Community, title, content)
+	if err != nil {
+		return nostr.Event{}, err
+	}
+	evt.Tags = append(evt.Tags, editTags(original)...)
+	if posted := original.Posted(); !posted.IsZero() {
+		evt.Tags = append(evt.Tags, nostr.Tag{publishedAtTag, strconv.FormatInt(posted.Unix(), 10)})
+	}
+	return evt, nil
+}
+
+// EditPost publishes a revision of one of the user's own posts and asks for the
+// original to be deleted. The revision is returned even if the deletion request
+// fails, since readers already prefer the newer version.
+func (c *NostrClient) EditPost(original model.Post, title, content string) (model.Post, error) {
+	evt, err := c.editEvent(original, title, content)
+	if err != nil {
+		return model.Post{}, err
+	}
+
+	if err := c.signAndPublish(&evt); err != nil {
+		return model.Post{}, err
+	}
+
+	if err := c.DeleteEvent(original.ID, original.PubKey, original.Kind); err != nil {
+		slog.Warn("Could not request deletion of the edited post", "id", original.ID, "error", err)
+	}
+
+	c.postCache.clear()
+	c.threadCache.clear()
+
+	return c.eventToPost(evt), nil
+}
+
+// PreviewEdit shows what EditPost would send, without signing or sending it.
+func (c *NostrClient) PreviewEdit(original model.Post, title, content string) (model.PublishPreview, error) {
+	evt, err := c.editEvent(original, title, content)
+	if err != nil {
+		return model.PublishPreview{}, err
+	}
+	return c.postPreview(evt), nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"tuistr/config"
+	"tuistr/model"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestRevisionsReplaceTheirOriginal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.NewConfig()
+	cfg.Nostr.Offline = true
+	c, err := NewNostrClient(cfg)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer c.Close()
+
+	author, stranger := nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()
+	original := signedEvent(t, author, nostr.Event{Kind: 1111, Tags: communityTags("t:nostr"), Content: "frist"})
+	reply := signedEvent(t, stranger, nostr.Event{Kind: 1111, Tags: replyTags(c.eventToPost(original), model.Comment{}), Content: "typo"})
+
+	c.store.save(original, reply)
+
+	revisionTags := append(postTags("t:nostr", "first"), editTags(c.eventToPost(original))...)
+	revision := signedEvent(t, author, nostr.Event{Kind: 1111, Tags: revisionTags, Content: "first"})
+	c.store.save(revision)
+
+	// only the author can supersede a post
+	hijackTags := append(communityTags("t:nostr"), editTags(c.eventToPost(revision))...)
+	hijack := signedEvent(t, stranger, nostr.Event{Kind: 1111, Tags: hijackTags, Content: "mine now"})
+	upvote := signedEvent(t, stranger, nostr.Event{Kind: 7, Tags: nostr.Tags{{"e", original.ID}, {"p", original.PubKey}}, Content: "+"})
+	c.store.save(hijack, upvote)
+
+	posts, err := c.GetCommunityPosts("t:nostr", "")
+	if err != nil {
+		t.Fatalf("could not load posts: %v", err)
+	}
+	ids := make([]string, 0, len(posts.Posts))
+	for _, post := range posts.Posts {
+		ids = append(ids, post.ID)
+	}
+	if len(ids) != 2 || !slices.Contains(ids, revision.ID) || !slices.Contains(ids, hijack.ID) {
+		t.Fatalf("expected the revision and the stranger's post, got %v", ids)
+	}
+
+	post := posts.Posts[slices.Index(ids, revision.ID)]
+	if !post.Edited() || !slices.Equal(post.Revises, []string{original.ID}) || post.Subject != "first" {
+		t.Fatalf("expected the revision to name its original, got %+v", post)
+	}
+
+	thread, err := c.GetThread(post)
+	if err != nil {
+		t.Fatalf("could not load thread: %v", err)
+	}
+	if !thread.PostEdited || len(thread.Comments) != 1 || thread.Comments[0].ID != reply.ID {
+		t.Fatalf("expected the reply to the original in the revision's thread, got %+v", thread)
+	}
+
+	stolen := posts.Posts[slices.Index(ids, hijack.ID)]
+	if stolen.Edited() {
+		t.Fatalf("expected the stranger's post to revise nothing, got %v", stolen.Revises)
+	}
+	thread, err = c.GetThread(stolen)
+	if err != nil {
+		t.Fatalf("could not load thread: %v", err)
+	}
+	if thread.PostEdited || len(thread.Comments) != 0 {
+		t.Fatalf("expected no comments in the stranger's thread, got %+v", thread.Comments)
+	}
+
+	scores := c.FetchPostScores([]model.Post{post, stolen})
+	if scores[revision.ID] != 1 || scores[hijack.ID] != 0 {
+		t.Fatalf("expected the upvote to count for the revision only, got %v", scores)
+	}
+}
+
+func TestEditTagsKeepEarlierVersions(t *testing.T) {
+	post := model.Post{ID: "b", Revises: []string{"a"}}
+	tags := editTags(post)
+	want := nostr.Tags{{"revises", "a"}, {"revises", "b"}}
+	if len(tags) != len(want) {
+		t.Fatalf("expected %v, got %v", want, tags)
+	}
+	for i := range want {
+		if !slices.Equal(tags[i], want[i]) {
+			t.Fatalf("expected %v, got %v", want, tags)
+		}
+	}
+}
+
+func TestPublishedAtFollowsTheFirstVersion(t *testing.T) {
+	revision := nostr.Event{CreatedAt: 200, Tags: nostr.Tags{{"revises", strings.Repeat("a", 64)}, {"published_at", "100"}}}
+	if got := publishedAt(revision).Unix(); got != 100 {
+		t.Fatalf("expected the original's time, got %d", got)
+	}
+
+	revision.Tags[1] = nostr.Tag{"published_at", "300"}
+	if got := publishedAt(revision).Unix(); got != 200 {
+		t.Fatalf("expected a future published_at to be ignored, got %d", got)
+	}
+
+	post := nostr.Event{CreatedAt: 200, Tags: nostr.Tags{{"published_at", "100"}}}
+	if got := publishedAt(post).Unix(); got != 200 {
+		t.Fatalf("expected posts that revise nothing to keep their own time, got %d", got)
+	}
+}
@@ -0,0 +1,396 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"tuistr/model"
+	"tuistr/utils"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip11"
+)
+
+const (
+	maxRelayNameLength        = 64
+	maxRelayDescriptionLength = 500
+	maxRelayErrorLength       = 200
+)
+
+var ErrInvalidRelay = errors.New("relay url must start with ws:// or wss://")
+
+// relayHealth keeps per-relay connection and traffic stats for the session.
+type relayHealth struct {
+	mu     sync.Mutex
+	relays map[string]*model.RelayStatus
+	order  []string
+}
+
+func newRelayHealth(urls []string) *relayHealth {
+	h := &relayHealth{relays: make(map[string]*model.RelayStatus)}
+	for _, url := range urls {
+		h.track(url)
+	}
+	return h
+}
+
+// track returns the status for url, adding it if needed. Callers hold h.mu.
+func (h *relayHealth) track(url string) *model.RelayStatus {
+	url = nostr.NormalizeURL(url)
+	status, ok := h.relays[url]
+	if !ok {
+		status = &model.RelayStatus{URL: url}
+		h.relays[url] = status
+		h.order = append(h.order, url)
+	}
+	return status
+}
+
+func (h *relayHealth) connected(url string, latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := h.track(url)
+	if err != nil {
+		status.LastError = relayError{err}.Error()
+		return
+	}
+	status.Latency = latency
+}
+
+// responded folds the time a relay took to answer a request into its latency, as
+// a moving average so one slow query doesn't swing it.
+func (h *relayHealth) responded(url string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := h.track(url)
+	if status.Latency == 0 {
+		status.Latency = latency
+		return
+	}
+	status.Latency = (3*status.Latency + latency) / 4
+}
+
+func (h *relayHealth) received(url string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status := h.track(url)
+	status.EventsReceived++
+	if status.Auth == model.AuthPending {
+		status.Auth = model.AuthOK
+	}
+}
+
+func (h *relayHealth) published(url string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := h.track(url)
+	if err != nil {
+		status.PublishFailed++
+		status.LastError = relayError{err}.Error()
+		if isAuthError(err) {
+			status.Auth = authFailed(status.Auth)
+		}
+		return
+	}
+	status.PublishOK++
+	if status.Auth == model.AuthPending {
+		status.Auth = model.AuthOK
+	}
+}
+
+// setAuth records how authentication with url went; err, if any, becomes the
+// relay's last error.
+func (h *relayHealth) setAuth(url string, state model.AuthState, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := h.track(url)
+	status.Auth = state
+	if err != nil {
+		status.LastError = "auth: " + relayError{err}.Error()
+	}
+}
+
+func (h *relayHealth) allowAuth(url string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := h.track(url)
+	status.AuthAllowed = true
+	if status.LastError == "auth: "+ErrAuthNotAllowed.Error() {
+		status.Auth = model.AuthNone
+		status.LastError = ""
+	}
+}
+
+func (h *relayHealth) authAllowed(url string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status, ok := h.relays[nostr.NormalizeURL(url)]
+	return ok && status.AuthAllowed
+}
+
+func (h *relayHealth) resetAuth(url string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if status, ok := h.relays[nostr.NormalizeURL(url)]; ok {
+		status.Auth = model.AuthNone
+	}
+}
+
+func (h *relayHealth) setDisabled(url string, disabled bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.track(url).Disabled = disabled
+}
+
+func (h *relayHealth) disabled(url string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status, ok := h.relays[nostr.NormalizeURL(url)]
+	return ok && status.Disabled
+}
+
+func (h *relayHealth) snapshot() []model.RelayStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	statuses := make([]model.RelayStatus, 0, len(h.order))
+	for _, url := range h.order {
+		statuses = append(statuses, *h.relays[url])
+	}
+	return statuses
+}
+
+// activeRelays returns the enabled relays every query and subscription goes to.
+func (c *NostrClient) activeRelays() []string {
+	c.relaysMu.RLock()
+	defer c.relaysMu.RUnlock()
+	return c.enabledRelays(slices.Clone(c.relays))
+}
+
+// activeWriteRelays returns the enabled relays we publish to.
+func (c *NostrClient) activeWriteRelays() []string {
+	c.relaysMu.RLock()
+	defer c.relaysMu.RUnlock()
+	return c.enabledRelays(slices.Clone(c.writeRelays))
+}
+
+// isConfiguredRelay reports whether url is one of the configured read or write
+// relays, enabled or not.
+func (c *NostrClient) isConfiguredRelay(url string) bool {
+	c.relaysMu.RLock()
+	defer c.relaysMu.RUnlock()
+	return slices.ContainsFunc(c.relays, sameRelay(url)) || slices.ContainsFunc(c.writeRelays, sameRelay(url))
+}
+
+// connectRelay connects to url, recording how long the handshake took as a first
+// latency until its queries are timed.
+func (c *NostrClient) connectRelay(url string) error {
+	start := time.Now()
+	_, err := c.pool.EnsureRelay(url)
+	c.health.connected(url, time.Since(start), err)
+	return err
+}
+
+func (c *NostrClient) relayConnected(url string) bool {
+	relay, ok := c.pool.Relays.Load(nostr.NormalizeURL(url))
+	return ok && relay != nil && relay.IsConnected()
+}
+
+// RelayStatuses returns the health of every relay used this session.
+func (c *NostrClient) RelayStatuses() []model.RelayStatus {
+	statuses := c.health.snapshot()
+	for i, status := range statuses {
+		statuses[i].Connected = c.relayConnected(status.URL)
+	}
+	return statuses
+}
+
+// ReconnectRelay drops the connection to url and dials it again.
+func (c *NostrClient) ReconnectRelay(url string) error {
+	if c.offline {
+		return ErrOffline
+	}
+	c.closeRelay(nostr.NormalizeURL(url))
+	return c.connectRelay(url)
+}
+
+// SetRelayEnabled stops or resumes using url for this session. The config file is
+// left alone.
+func (c *NostrClient) SetRelayEnabled(url string, enabled bool) error {
+	url = nostr.NormalizeURL(url)
+	c.health.setDisabled(url, !enabled)
+
+	if !enabled {
+		c.closeRelay(url)
+		return nil
+	}
+	if c.offline {
+		return nil
+	}
+	return c.connectRelay(url)
+}
+
+// RelayDisabled reports whether url was disabled for this session.
+func (c *NostrClient) RelayDisabled(url string) bool {
+	return c.health.disabled(url)
+}
+
+// AddRelay starts using url for the rest of the session.
+func (c *NostrClient) AddRelay(url string) error {
+	if !isRelayURL(url) {
+		return ErrInvalidRelay
+	}
+
+	url = nostr.NormalizeURL(url)
+	if url == "" {
+		return ErrInvalidRelay
+	}
+
+	c.relaysMu.Lock()
+	if !slices.ContainsFunc(c.relays, sameRelay(url)) {
+		c.relays = append(c.relays, url)
+	}
+	if !slices.ContainsFunc(c.writeRelays, sameRelay(url)) {
+		c.writeRelays = append(c.writeRelays, url)
+	}
+	c.relaysMu.Unlock()
+
+	return c.SetRelayEnabled(url, true)
+}
+
+// SetRelays replaces the relays we read from and publish to, connecting to new
+// ones and dropping connections to relays no longer listed.
+func (c *NostrClient) SetRelays(read, write []string) error {
+	if len(read) == 0 {
+		return ErrNoRelays
+	}
+
+	c.relaysMu.Lock()
+	previous := append(slices.Clone(c.relays), c.writeRelays...)
+	c.relays, c.writeRelays = slices.Clone(read), slices.Clone(write)
+	c.relaysMu.Unlock()
+
+	current := append(slices.Clone(read), write...)
+	for _, url := range previous {
+		if !slices.ContainsFunc(current, sameRelay(url)) {
+			c.closeRelay(nostr.NormalizeURL(url))
+		}
+	}
+
+	var errs []error
+	for _, url := range current {
+		c.health.setDisabled(url, false)
+		if !c.offline {
+			if err := c.connectRelay(url); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sameRelay(url string) func(string) bool {
+	url = nostr.NormalizeURL(url)
+	return func(other string) bool {
+		return nostr.NormalizeURL(other) == url
+	}
+}
+
+// TestRelay dials url on a throwaway connection and reports how long it took.
+func (c *NostrClient) TestRelay(url string) (time.Duration, error) {
+	if !isRelayURL(url) {
+		return 0, ErrInvalidRelay
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	relay, err := nostr.RelayConnect(ctx, url)
+	if err != nil {
+		return 0, relayError{err}
+	}
+	latency := time.Since(start)
+	relay.Close()
+
+	return latency, nil
+}
+
+// RelayInfo fetches the relay's NIP-11 information document.
+func (c *NostrClient) RelayInfo(url string) (model.RelayInfo, error) {
+	if !isRelayURL(url) {
+		return model.RelayInfo{}, ErrInvalidRelay
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	doc, err := nip11.Fetch(ctx, url)
+	if err != nil {
+		return model.RelayInfo{}, relayError{err}
+	}
+
+	return relayInfoFromDocument(doc), nil
+}
+
+// relayError carries an error whose text may quote what a relay sent back, and
+// sanitizes that text before it reaches the terminal.
+type relayError struct {
+	err error
+}
+
+func (e relayError) Error() string {
+	return utils.SanitizeLine(e.err.Error(), maxRelayErrorLength)
+}
+
+func (e relayError) Unwrap() error {
+	return e.err
+}
+
+// relayInfoFromDocument keeps the parts of a NIP-11 document we show. The relay
+// writes the text fields, so they are sanitized and clamped like profile names.
+func relayInfoFromDocument(doc nip11.RelayInformationDocument) model.RelayInfo {
+	info := model.RelayInfo{
+		URL:         doc.URL,
+		Name:        utils.SanitizeLine(doc.Name, maxRelayNameLength),
+		Description: utils.SanitizeLine(doc.Description, maxRelayDescriptionLength),
+		Software:    utils.SanitizeLine(doc.Software+" "+doc.Version, maxRelayNameLength),
+	}
+
+	// supported_nips is untyped in the wild: numbers, and sometimes strings
+	for _, nip := range doc.SupportedNIPs {
+		switch v := nip.(type) {
+		case float64:
+			info.SupportedNIPs = append(info.SupportedNIPs, int(v))
+		case int:
+			info.SupportedNIPs = append(info.SupportedNIPs, v)
+		case string:
+			if n, err := strconv.Atoi(v); err == nil {
+				info.SupportedNIPs = append(info.SupportedNIPs, n)
+			}
+		}
+	}
+	slices.Sort(info.SupportedNIPs)
+	info.SupportedNIPs = slices.Compact(info.SupportedNIPs)
+
+	if limits := doc.Limitation; limits != nil {
+		info.MaxMessageLength = limits.MaxMessageLength
+		info.MaxSubscriptions = limits.MaxSubscriptions
+		info.MaxLimit = limits.MaxLimit
+		info.MaxContentLength = limits.MaxContentLength
+		info.AuthRequired = limits.AuthRequired
+		info.PaymentRequired = limits.PaymentRequired
+		info.RestrictedWrites = limits.RestrictedWrites
+	}
+
+	return info
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip11"
+)
+
+func TestRelayHealthCountsTraffic(t *testing.T) {
+	health := newRelayHealth([]string{"wss://a.example.com/"})
+
+	health.received("wss://a.example.com")
+	health.received("wss://a.example.com")
+	health.published("wss://a.example.com", nil)
+	health.published("wss://b.example.com", errors.New("blocked: rate limited"))
+
+	statuses := health.snapshot()
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 tracked relays, got %+v", statuses)
+	}
+
+	a, b := statuses[0], statuses[1]
+	if a.URL != "wss://a.example.com" || a.EventsReceived != 2 || a.PublishOK != 1 || a.LastError != "" {
+		t.Fatalf("unexpected status for configured relay: %+v", a)
+	}
+	if b.URL != "wss://b.example.com" || b.PublishFailed != 1 || b.LastError != "blocked: rate limited" {
+		t.Fatalf("unexpected status for failing relay: %+v", b)
+	}
+}
+
+func TestRelayHealthAveragesLatency(t *testing.T) {
+	health := newRelayHealth([]string{"wss://a.example.com"})
+
+	health.connected("wss://a.example.com", 400*time.Millisecond, nil)
+	health.responded("wss://a.example.com", 80*time.Millisecond)
+	if got := health.snapshot()[0].Latency; got != 320*time.Millisecond {
+		t.Fatalf("expected handshake latency averaged with the query, got %s", got)
+	}
+
+	health.responded("wss://b.example.com", 50*time.Millisecond)
+	if got := health.snapshot()[1].Latency; got != 50*time.Millisecond {
+		t.Fatalf("expected first measurement to be taken as is, got %s", got)
+	}
+}
+
+func TestRelayInfoFromDocument(t *testing.T) {
+	doc := nip11.RelayInformationDocument{
+		URL:           "wss://a.example.com",
+		Name:          " Relay A ",
+		Software:      "strfry",
+		Version:       "1.0",
+		SupportedNIPs: []any{float64(42), "11", float64(1), float64(11), "x"},
+		Limitation:    &nip11.RelayLimitationDocument{MaxLimit: 500, AuthRequired: true},
+	}
+
+	info := relayInfoFromDocument(doc)
+	if info.Name != "Relay A" || info.Software != "strfry 1.0" {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if !slices.Equal(info.SupportedNIPs, []int{1, 11, 42}) {
+		t.Fatalf("supported nips = %v", info.SupportedNIPs)
+	}
+	if info.MaxLimit != 500 || !info.AuthRequired {
+		t.Fatalf("expected limits to be copied, got %+v", info)
+	}
+}
+
+func TestRelayInfoDropsEscapeSequences(t *testing.T) {
+	doc := nip11.RelayInformationDocument{
+		Name:        "evil\x1b]0;owned\x07\nrelay",
+		Description: "\x1b[2J" + strings.Repeat("spam ", 200),
+		Software:    "\u009b31mstrfry",
+	}
+
+	info := relayInfoFromDocument(doc)
+	for _, field := range []string{info.Name, info.Description, info.Software} {
+		if strings.ContainsAny(field, "\x1b\x07\n\u009b") {
+			t.Fatalf("expected control characters to be dropped, got %q", field)
+		}
+	}
+	if info.Name != "evil]0;owned relay" || info.Software != "31mstrfry" {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if n := len([]rune(info.Description)); n != maxRelayDescriptionLength {
+		t.Fatalf("expected description clamped to %d runes, got %d", maxRelayDescriptionLength, n)
+	}
+
+	err := relayError{errors.New("failed: \x1b[31mboom")}
+	if got := err.Error(); got != "failed: [31mboom" {
+		t.Fatalf("expected a sanitized error, got %q", got)
+	}
+}
+
+func TestSetRelayEnabledForSession(t *testing.T) {
+	relays := []string{"wss://a.example.com", "wss://b.example.com"}
+	c := &NostrClient{
+		pool:    nostr.NewSimplePool(context.Background()),
+		relays:  append([]string{}, relays...),
+		health:  newRelayHealth(relays),
+		offline: true,
+	}
+
+	c.SetRelayEnabled("wss://a.example.com/", false)
+	if got := c.activeRelays(); !slices.Equal(got, []string{"wss://b.example.com"}) {
+		t.Fatalf("expected a to be dropped, got %v", got)
+	}
+	if !c.RelayDisabled("wss://a.example.com") {
+		t.Fatalf("expected a to be marked disabled")
+	}
+	if !slices.Equal(c.enabledRelays([]string{"wss://a.example.com", "wss://c.example.com"}), []string{"wss://c.example.com"}) {
+		t.Fatalf("expected disabled relays to be skipped for outbox routing")
+	}
+
+	if err := c.AddRelay("https://c.example.com"); !errors.Is(err, ErrInvalidRelay) {
+		t.Fatalf("expected invalid relay error, got %v", err)
+	}
+	if err := c.AddRelay("wss://C.example.com"); err != nil {
+		t.Fatalf("unexpected error adding relay: %v", err)
+	}
+	c.SetRelayEnabled("wss://a.example.com", true)
+
+	want := []string{"wss://a.example.com", "wss://b.example.com", "wss://c.example.com"}
+	if got := c.activeRelays(); !slices.Equal(got, want) {
+		t.Fatalf("active relays = %v, want %v", got, want)
+	}
+	if got := c.activeWriteRelays(); !slices.Equal(got, []string{"wss://c.example.com"}) {
+		t.Fatalf("expected the added relay to be written to, got %v", got)
+	}
+}
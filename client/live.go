@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"time"
+	"tuistr/model"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func (c *NostrClient) SubscribeFeaturedPosts(ctx context.Context, since time.Time) <-chan model.Post {
+	return c.subscribePosts(ctx, c.featured, since)
+}
+
+func (c *NostrClient) SubscribeCommunityPosts(ctx context.Context, community string, since time.Time) <-chan model.Post {
+	return c.subscribePosts(ctx, []string{community}, since)
+}
+
+// subscribePosts streams top level posts for the communities that are published
+// from since on, until ctx is cancelled. Callers pass the time their fetch
+// started so nothing published while it ran is missed. Offline there is nothing
+// to stream and the channel is nil. Like the feed fetch it listens on the
+// configured relays: a community has no author whose relays we could add.
+func (c *NostrClient) subscribePosts(ctx context.Context, communities []string, since time.Time) <-chan model.Post {
+	if c.offline {
+		return nil
+	}
+
+	filter := c.postsFilter(communities, "")
+	filter.Limit = 0
+	filter.Since = liveSince(since)
+
+	out := make(chan model.Post)
+	go func() {
+		defer close(out)
+		for evt := range c.subscribe(ctx, c.activeRelays(), filter) {
+			if !isTopLevel(evt) {
+				continue
+			}
+			c.postCache.clear()
+
+			select {
+			case out <- c.eventToPost(evt):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// SubscribeThread streams replies to a thread that are published from since on,
+// until ctx is cancelled. It listens on the relays GetThread reads the thread
+// from, so replies sent only to the author's relays arrive live too.
+func (c *NostrClient) SubscribeThread(ctx context.Context, post model.Post, since time.Time) <-chan model.Comment {
+	if c.offline {
+		return nil
+	}
+
+	out := make(chan model.Comment)
+	go func() {
+		defer close(out)
+
+		// Looking up the author's relay list may take a round trip, so it
+		// happens here rather than in the caller
+		lookup, cancel := context.WithTimeout(ctx, c.timeout)
+		relays, release := c.threadRelaysFor(lookup, post.PubKey)
+		cancel()
+		defer release()
+
+		filters := threadFilters(post.EventIDs(), 0)
+		merged := make(chan nostr.Event)
+		done := make(chan struct{}, len(filters))
+		for _, filter := range filters {
+			filter.Since = liveSince(since)
+			go func(f nostr.Filter) {
+				for evt := range c.subscribe(ctx, relays, f) {
+					select {
+					case merged <- evt:
+					case <-ctx.Done():
+					}
+				}
+				done <- struct{}{}
+			}(filter)
+		}
+
+		// Replies usually match both the e and E filter
+		seen := make(map[string]bool)
+		remaining := len(filters)
+		for remaining > 0 {
+			select {
+			case evt := <-merged:
+				if seen[evt.ID] || slices.Contains(post.EventIDs(), evt.ID) {
+					continue
+				}
+				seen[evt.ID] = true
+				c.threadCache.clear()
+
+				// Depth is placed by the viewer, which knows the parent
+				select {
+				case out <- c.eventToComment(evt, 0):
+				case <-ctx.Done():
+					return
+				}
+			case <-done:
+				remaining--
+			}
+		}
+	}()
+
+	return out
+}
+
+// liveSince is the since filter for a subscription, defaulting to now.
+func liveSince(since time.Time) *nostr.Timestamp {
+	ts := nostr.Now()
+	if !since.IsZero() {
+		ts = nostr.Timestamp(since.Unix())
+	}
+	return &ts
+}
+
+// subscribe opens a live subscription on relays and stores what arrives.
+func (c *NostrClient) subscribe(ctx context.Context, relays []string, filter nostr.Filter) <-chan nostr.Event {
+	events := c.pool.SubscribeMany(ctx, relays, filter)
+
+	out := make(chan nostr.Event)
+	go func() {
+		defer close(out)
+		for ev := range events {
+			if ev.Event == nil {
+				continue
+			}
+
+			c.health.received(ev.Relay.URL)
+			c.store.save(*ev.Event)
+
+			select {
+			case out <- *ev.Event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
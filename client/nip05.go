@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr/nip05"
+)
+
+const (
+	nip05TTL         = 6 * time.Hour
+	nip05Concurrency = 8
+)
+
+// nip05Resolver fetches the nostr.json document that backs an identifier.
+type nip05Resolver func(ctx context.Context, name, domain string) (nip05.WellKnownResponse, error)
+
+// newHTTPNip05Resolver queries <baseURL(domain)>/.well-known/nostr.json. Tests swap
+// baseURL for a local server; the default resolves against https://<domain>.
+func newHTTPNip05Resolver(client *http.Client, baseURL func(domain string) string) nip05Resolver {
+	if baseURL == nil {
+		baseURL = func(domain string) string { return "https://" + domain }
+	}
+
+	return func(ctx context.Context, name, domain string) (nip05.WellKnownResponse, error) {
+		var result nip05.WellKnownResponse
+
+		endpoint := fmt.Sprintf("%s/.well-known/nostr.json?name=%s", baseURL(domain), url.QueryEscape(name))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return result, err
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			return result, err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return result, fmt.Errorf("nip05 lookup returned %s", res.Status)
+		}
+
+		err = json.NewDecoder(res.Body).Decode(&result)
+		return result, err
+	}
+}
+
+// NIP-05 forbids following redirects for the well-known document
+func nip05HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// nip05Verifier checks that an identifier's domain vouches for a pubkey and
+// remembers the outcome for a while.
+type nip05Verifier struct {
+	resolve nip05Resolver
+	mu      sync.Mutex
+	results *simpleCache[bool]
+}
+
+func newNip05Verifier(resolve nip05Resolver) *nip05Verifier {
+	return &nip05Verifier{
+		resolve: resolve,
+		results: newSimpleCache[bool](),
+	}
+}
+
+func (v *nip05Verifier) cached(identifier, pubKey string) (verified bool, ok bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.results.get(identifier + "|" + pubKey)
+}
+
+func (v *nip05Verifier) verify(ctx context.Context, identifier, pubKey string) bool {
+	if verified, ok := v.cached(identifier, pubKey); ok {
+		return verified
+	}
+
+	verified := false
+	if name, domain, err := nip05.ParseIdentifier(strings.TrimSpace(identifier)); err == nil {
+		if doc, err := v.resolve(ctx, name, domain); err == nil {
+			verified = strings.EqualFold(doc.Names[name], pubKey)
+		}
+	}
+
+	// Don't remember failures caused by our own deadline
+	if ctx.Err() != nil && !verified {
+		return false
+	}
+
+	v.mu.Lock()
+	v.results.set(identifier+"|"+pubKey, verified, time.Now().Add(nip05TTL))
+	v.mu.Unlock()
+
+	return verified
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr/nip05"
+)
+
+func TestNip05VerifierAgainstLocalServer(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path != "/.well-known/nostr.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"names":{"alice":"abc123"}}`))
+	}))
+	defer server.Close()
+
+	resolver := newHTTPNip05Resolver(nip05HTTPClient(time.Second), func(string) string { return server.URL })
+	verifier := newNip05Verifier(resolver)
+	ctx := context.Background()
+
+	if !verifier.verify(ctx, "alice@example.com", "abc123") {
+		t.Fatalf("expected alice to verify")
+	}
+	if verifier.verify(ctx, "alice@example.com", "def456") {
+		t.Fatalf("expected mismatched pubkey to fail")
+	}
+	if verifier.verify(ctx, "bob@example.com", "abc123") {
+		t.Fatalf("expected unknown name to fail")
+	}
+
+	before := hits.Load()
+	if verified, ok := verifier.cached("alice@example.com", "abc123"); !ok || !verified {
+		t.Fatalf("expected cached verification result")
+	}
+	verifier.verify(ctx, "alice@example.com", "abc123")
+	if hits.Load() != before {
+		t.Fatalf("expected cached result to skip the lookup")
+	}
+}
+
+func TestNip05VerifierRejectsInvalidIdentifier(t *testing.T) {
+	verifier := newNip05Verifier(func(ctx context.Context, name, domain string) (nip05.WellKnownResponse, error) {
+		t.Fatalf("resolver should not be called for invalid identifiers")
+		return nip05.WellKnownResponse{}, nil
+	})
+
+	if verifier.verify(context.Background(), "not an identifier", "abc123") {
+		t.Fatalf("expected invalid identifier to fail")
+	}
+}
@@ -0,0 +1,273 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	relayListTTL       = time.Hour
+	relayListBatchSize = 100
+	relaysPerAuthor    = 2
+	maxOutboxRelays    = 16
+)
+
+// relayList is an author's NIP-65 (kind 10002) relay list. Authors publish to their
+// write relays and read mentions from their read relays.
+type relayList struct {
+	Read  []string
+	Write []string
+}
+
+func parseRelayList(evt nostr.Event) relayList {
+	var list relayList
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 || tag[0] != "r" {
+			continue
+		}
+
+		if !isRelayURL(tag[1]) {
+			continue
+		}
+		url := nostr.NormalizeURL(tag[1])
+
+		marker := ""
+		if len(tag) >= 3 {
+			marker = tag[2]
+		}
+
+		if marker != "write" && !slices.Contains(list.Read, url) {
+			list.Read = append(list.Read, url)
+		}
+		if marker != "read" && !slices.Contains(list.Write, url) {
+			list.Write = append(list.Write, url)
+		}
+	}
+	return list
+}
+
+func isRelayURL(url string) bool {
+	url = strings.ToLower(strings.TrimSpace(url))
+	return strings.HasPrefix(url, "wss://") || strings.HasPrefix(url, "ws://")
+}
+
+// outboxPool bounds how many relays beyond the configured ones we keep open. Extra
+// relays are handed out least recently used last and closed once they fall off the
+// end of the pool. A relay still used by a request when it falls off is closed
+// when that request releases it. Relays that shared reports as configured are
+// routed like any other but never count against the pool or get closed by it,
+// since the feed and publishing rely on those connections.
+type outboxPool struct {
+	mu      sync.Mutex
+	max     int
+	extra   []string
+	inUse   map[string]int
+	retired map[string]bool
+	shared  func(url string) bool
+	evict   func(url string)
+}
+
+func newOutboxPool(max int, shared func(url string) bool, evict func(url string)) *outboxPool {
+	return &outboxPool{max: max, inUse: make(map[string]int), retired: make(map[string]bool), shared: shared, evict: evict}
+}
+
+// route returns the base relays plus up to max of the candidate relays. The extra
+// relays stay open until the returned release func is called.
+func (p *outboxPool) route(base, candidates []string) ([]string, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	relays := append([]string{}, base...)
+	for i, url := range relays {
+		relays[i] = nostr.NormalizeURL(url)
+	}
+
+	var used []string
+	for _, url := range candidates {
+		url = nostr.NormalizeURL(url)
+		if url == "" || slices.Contains(relays, url) {
+			continue
+		}
+		if p.isShared(url) {
+			relays = append(relays, url)
+			continue
+		}
+		if len(used) == p.max {
+			break
+		}
+		used = append(used, url)
+		relays = append(relays, url)
+		p.inUse[url]++
+		delete(p.retired, url)
+
+		// Move to the most recently used end
+		if i := slices.Index(p.extra, url); i >= 0 {
+			p.extra = slices.Delete(p.extra, i, i+1)
+		}
+		p.extra = append(p.extra, url)
+	}
+
+	for len(p.extra) > p.max {
+		p.retire(p.extra[0])
+		p.extra = p.extra[1:]
+	}
+
+	var once sync.Once
+	return relays, func() {
+		once.Do(func() { p.release(used) })
+	}
+}
+
+func (p *outboxPool) release(urls []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, url := range urls {
+		if p.inUse[url]--; p.inUse[url] > 0 {
+			continue
+		}
+		delete(p.inUse, url)
+		if p.retired[url] {
+			delete(p.retired, url)
+			p.close(url)
+		}
+	}
+}
+
+// retire closes a relay that fell off the pool, or marks it to be closed once the
+// requests using it are done.
+func (p *outboxPool) retire(url string) {
+	if p.inUse[url] > 0 {
+		p.retired[url] = true
+		return
+	}
+	p.close(url)
+}
+
+func (p *outboxPool) close(url string) {
+	// a relay may have been configured since it joined the pool
+	if p.evict != nil && !p.isShared(url) {
+		p.evict(url)
+	}
+}
+
+func (p *outboxPool) isShared(url string) bool {
+	return p.shared != nil && p.shared(url)
+}
+
+// closeRelay drops a relay connection from the pool; it reconnects if used again.
+// AUTH is per connection, so the relay has to challenge us again.
+func (c *NostrClient) closeRelay(url string) {
+	if relay, ok := c.pool.Relays.LoadAndDelete(url); ok && relay != nil {
+		relay.Close()
+		c.health.resetAuth(url)
+	}
+}
+
+// fetchRelayLists returns the newest known relay list of each author, asking the
+// configured relays for the ones we haven't seen recently.
+func (c *NostrClient) fetchRelayLists(ctx context.Context, pubKeys []string) map[string]relayList {
+	lists := make(map[string]relayList, len(pubKeys))
+
+	var missing []string
+	for _, pk := range pubKeys {
+		if _, ok := lists[pk]; ok || pk == "" {
+			continue
+		}
+		if list, ok := c.relayLists.get(pk); ok {
+			lists[pk] = list
+		} else if !slices.Contains(missing, pk) {
+			missing = append(missing, pk)
+		}
+	}
+
+	for start := 0; start < len(missing); start += relayListBatchSize {
+		batch := missing[start:min(start+relayListBatchSize, len(missing))]
+		events := c.collect(ctx, nostr.Filter{Kinds: []int{10002}, Authors: batch})
+
+		newest := make(map[string]nostr.Event, len(events))
+		for _, evt := range events {
+			if existing, ok := newest[evt.PubKey]; !ok || evt.CreatedAt > existing.CreatedAt {
+				newest[evt.PubKey] = evt
+			}
+		}
+
+		for _, pk := range batch {
+			list := parseRelayList(newest[pk])
+			lists[pk] = list
+			c.relayLists.set(pk, list, time.Now().Add(relayListTTL))
+		}
+	}
+
+	return lists
+}
+
+// outboxRelays picks a few write and/or read relays of each author and routes them
+// through the bounded pool, always including the configured relays. Callers call
+// release once they are done with the relays.
+func (c *NostrClient) outboxRelays(ctx context.Context, pubKeys []string, write, read bool) (relays []string, release func()) {
+	if c.offline || len(pubKeys) == 0 {
+		return c.activeRelays(), func() {}
+	}
+
+	lists := c.fetchRelayLists(ctx, pubKeys)
+
+	var candidates []string
+	for _, pk := range pubKeys {
+		list := lists[pk]
+		if write {
+			candidates = append(candidates, list.Write[:min(relaysPerAuthor, len(list.Write))]...)
+		}
+		if read {
+			candidates = append(candidates, list.Read[:min(relaysPerAuthor, len(list.Read))]...)
+		}
+	}
+
+	return c.outbox.route(c.activeRelays(), c.enabledRelays(candidates))
+}
+
+// readRelaysFor returns where the authors publish: their write relays.
+func (c *NostrClient) readRelaysFor(ctx context.Context, pubKeys []string) ([]string, func()) {
+	return c.outboxRelays(ctx, pubKeys, true, false)
+}
+
+// threadRelaysFor returns where replies to an author end up: the repliers publish
+// to their own write relays and to the author's read relays.
+func (c *NostrClient) threadRelaysFor(ctx context.Context, pubKey string) ([]string, func()) {
+	return c.outboxRelays(ctx, []string{pubKey}, true, true)
+}
+
+// publishRelays returns our own write relays plus the read relays of everyone the
+// event is addressed to.
+func (c *NostrClient) publishRelays(ctx context.Context, recipients []string) ([]string, func()) {
+	if c.offline {
+		return c.activeWriteRelays(), func() {}
+	}
+
+	var candidates []string
+	pubKey := c.PubKey()
+	if pubKey != "" {
+		candidates = append(candidates, c.fetchRelayLists(ctx, []string{pubKey})[pubKey].Write...)
+	}
+
+	lists := c.fetchRelayLists(ctx, recipients)
+	for _, pk := range recipients {
+		if pk == pubKey {
+			continue
+		}
+		read := lists[pk].Read
+		candidates = append(candidates, read[:min(relaysPerAuthor, len(read))]...)
+	}
+
+	return c.outbox.route(c.activeWriteRelays(), c.enabledRelays(candidates))
+}
+
+// enabledRelays drops relays the user disabled for this session.
+func (c *NostrClient) enabledRelays(urls []string) []string {
+	return slices.DeleteFunc(urls, c.health.disabled)
+}
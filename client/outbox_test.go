@@ -0,0 +1,107 @@
+package client
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestParseRelayListMarkers(t *testing.T) {
+	list := parseRelayList(nostr.Event{Kind: 10002, Tags: nostr.Tags{
+		{"r", "wss://both.example.com/"},
+		{"r", "wss://inbox.example.com", "read"},
+		{"r", "wss://outbox.example.com", "write"},
+		{"r", "https://not-a-relay.example.com/page"},
+		{"r", "wss://both.example.com"},
+		{"p", "wss://ignored.example.com"},
+	}})
+
+	wantRead := []string{"wss://both.example.com", "wss://inbox.example.com"}
+	wantWrite := []string{"wss://both.example.com", "wss://outbox.example.com"}
+	if !slices.Equal(list.Read, wantRead) {
+		t.Fatalf("read relays = %v, want %v", list.Read, wantRead)
+	}
+	if !slices.Equal(list.Write, wantWrite) {
+		t.Fatalf("write relays = %v, want %v", list.Write, wantWrite)
+	}
+}
+
+func TestOutboxPoolBoundsExtraRelays(t *testing.T) {
+	var evicted []string
+	pool := newOutboxPool(2, nil, func(url string) {
+		evicted = append(evicted, url)
+	})
+
+	base := []string{"wss://base.example.com"}
+	relays, release := pool.route(base, []string{"wss://base.example.com", "wss://a.example.com", "wss://b.example.com", "wss://c.example.com"})
+	release()
+	want := []string{"wss://base.example.com", "wss://a.example.com", "wss://b.example.com"}
+	if !slices.Equal(relays, want) {
+		t.Fatalf("route = %v, want %v", relays, want)
+	}
+	if len(evicted) != 0 {
+		t.Fatalf("expected nothing evicted yet, got %v", evicted)
+	}
+
+	// Reusing a keeps it fresh, so b is the least recently used relay
+	_, release = pool.route(base, []string{"wss://a.example.com"})
+	release()
+	_, release = pool.route(base, []string{"wss://c.example.com"})
+	release()
+
+	if !slices.Equal(evicted, []string{"wss://b.example.com"}) {
+		t.Fatalf("expected b to be evicted, got %v", evicted)
+	}
+}
+
+func TestOutboxPoolKeepsRelaysInUse(t *testing.T) {
+	var evicted []string
+	pool := newOutboxPool(1, nil, func(url string) {
+		evicted = append(evicted, url)
+	})
+
+	_, releaseA := pool.route(nil, []string{"wss://a.example.com"})
+	_, releaseB := pool.route(nil, []string{"wss://b.example.com"})
+	if len(evicted) != 0 {
+		t.Fatalf("expected a to stay open while in use, got %v", evicted)
+	}
+
+	releaseA()
+	releaseA()
+	if !slices.Equal(evicted, []string{"wss://a.example.com"}) {
+		t.Fatalf("expected a to close once released, got %v", evicted)
+	}
+
+	// Picked up again before its last user let go, so it is no longer retired
+	_, releaseC := pool.route(nil, []string{"wss://c.example.com"})
+	_, releaseB2 := pool.route(nil, []string{"wss://b.example.com"})
+	releaseB()
+	releaseC()
+	if !slices.Equal(evicted, []string{"wss://a.example.com", "wss://c.example.com"}) {
+		t.Fatalf("expected only a and c closed, got %v", evicted)
+	}
+	releaseB2()
+}
+
+func TestOutboxPoolNeverClosesConfiguredRelays(t *testing.T) {
+	configured := "wss://read.example.com"
+	var evicted []string
+	pool := newOutboxPool(1, func(url string) bool { return url == configured }, func(url string) {
+		evicted = append(evicted, url)
+	})
+
+	// publishing routes the configured read relay as a candidate
+	relays, release := pool.route([]string{"wss://write.example.com"}, []string{configured, "wss://a.example.com"})
+	release()
+	want := []string{"wss://write.example.com", configured, "wss://a.example.com"}
+	if !slices.Equal(relays, want) {
+		t.Fatalf("route = %v, want %v", relays, want)
+	}
+
+	_, release = pool.route(nil, []string{"wss://b.example.com", configured})
+	release()
+	if !slices.Equal(evicted, []string{"wss://a.example.com"}) {
+		t.Fatalf("expected only a to be evicted, got %v", evicted)
+	}
+}
@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+	"tuistr/model"
+	"tuistr/utils"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	profileTTL        = time.Hour
+	missingProfileTTL = 10 * time.Minute
+	profileBatchSize  = 100
+	maxNameLength     = 48
+)
+
+type profile struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Nip05       string `json:"nip05"`
+	createdAt   nostr.Timestamp
+}
+
+func (p profile) displayName() string {
+	if name := strings.TrimSpace(p.DisplayName); name != "" {
+		return name
+	}
+	return strings.TrimSpace(p.Name)
+}
+
+// profileStore caches kind 0 metadata by pubkey. Lookups that came back empty are
+// cached too (as a zero profile) so every page load doesn't ask for them again.
+type profileStore struct {
+	mu       sync.Mutex
+	profiles *simpleCache[profile]
+}
+
+func newProfileStore() *profileStore {
+	return &profileStore{profiles: newSimpleCache[profile]()}
+}
+
+func (s *profileStore) get(pubKey string) (profile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.profiles.get(pubKey)
+}
+
+func (s *profileStore) set(pubKey string, p profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Relays can return several kind 0 events per author; keep the newest
+	if existing, ok := s.profiles.get(pubKey); ok && existing.createdAt > p.createdAt {
+		return
+	}
+
+	ttl := profileTTL
+	if p.displayName() == "" {
+		ttl = missingProfileTTL
+	}
+	s.profiles.set(pubKey, p, time.Now().Add(ttl))
+}
+
+// missing returns the unique pubkeys that are not cached yet.
+func (s *profileStore) missing(pubKeys []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool, len(pubKeys))
+	var result []string
+	for _, pk := range pubKeys {
+		if pk == "" || seen[pk] {
+			continue
+		}
+		seen[pk] = true
+		if _, ok := s.profiles.get(pk); !ok {
+			result = append(result, pk)
+		}
+	}
+	return result
+}
+
+func parseProfile(evt nostr.Event) (profile, bool) {
+	var p profile
+	if err := json.Unmarshal([]byte(evt.Content), &p); err != nil {
+		return profile{}, false
+	}
+	p.Name = sanitizeName(p.Name)
+	p.DisplayName = sanitizeName(p.DisplayName)
+	p.createdAt = evt.CreatedAt
+	return p, true
+}
+
+// sanitizeName makes a profile name safe to print on one line: control
+// characters are dropped, whitespace runs collapse, check marks that could pass
+// for our NIP-05 badge are removed and the result is clamped to maxNameLength.
+func sanitizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '✓', '✔', '☑', '✅':
+			return -1
+		}
+		return r
+	}, name)
+	return utils.SanitizeLine(name, maxNameLength)
+}
+
+func (c *NostrClient) profileFor(pubKey string) model.Profile {
+	p, _ := c.profiles.get(pubKey)
+
+	result := model.Profile{Name: c.DisplayName(pubKey), Nip05: p.Nip05}
+	if p.Nip05 != "" {
+		result.Verified, _ = c.nip05.cached(p.Nip05, pubKey)
+	}
+	return result
+}
+
+// Seed the profile cache with metadata seen in earlier sessions.
+func (c *NostrClient) loadStoredProfiles() {
+	for _, evt := range c.store.query(nostr.Filter{Kinds: []int{0}}) {
+		if p, ok := parseProfile(evt); ok {
+			c.profiles.set(evt.PubKey, p)
+		}
+	}
+}
+
+// DisplayName returns the cached profile name for a pubkey, falling back to a
+// shortened key while metadata is unknown.
+func (c *NostrClient) DisplayName(pubKey string) string {
+	if p, ok := c.profiles.get(pubKey); ok {
+		if name := p.displayName(); name != "" {
+			return name
+		}
+	}
+	return utils.ShortenPubKey(pubKey)
+}
+
+// FetchProfiles looks up kind 0 metadata for the given authors in batches and
+// returns the profile of every author that is now known.
+func (c *NostrClient) FetchProfiles(pubKeys []string) map[string]model.Profile {
+	missing := c.profiles.missing(pubKeys)
+
+	if len(missing) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+
+		for start := 0; start < len(missing); start += profileBatchSize {
+			batch := missing[start:min(start+profileBatchSize, len(missing))]
+			relays, release := c.readRelaysFor(ctx, batch)
+			events := c.collectFrom(ctx, relays, nostr.Filter{Kinds: []int{0}, Authors: batch})
+			release()
+
+			found := make(map[string]bool, len(events))
+			for _, evt := range events {
+				if p, ok := parseProfile(evt); ok {
+					c.profiles.set(evt.PubKey, p)
+					found[evt.PubKey] = true
+				}
+			}
+
+			for _, pk := range batch {
+				if !found[pk] {
+					c.profiles.set(pk, profile{})
+				}
+			}
+		}
+	}
+
+	return c.knownProfiles(pubKeys)
+}
+
+// VerifyProfiles checks the NIP-05 identifiers of already fetched profiles and
+// returns them with their verification state.
+func (c *NostrClient) VerifyProfiles(pubKeys []string) map[string]model.Profile {
+	if c.offline {
+		return c.knownProfiles(pubKeys)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, nip05Concurrency)
+	seen := make(map[string]bool, len(pubKeys))
+
+	for _, pk := range pubKeys {
+		p, ok := c.profiles.get(pk)
+		if !ok || p.Nip05 == "" || seen[pk] {
+			continue
+		}
+		seen[pk] = true
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(identifier, pubKey string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.nip05.verify(ctx, identifier, pubKey)
+		}(p.Nip05, pk)
+	}
+
+	wg.Wait()
+	return c.knownProfiles(pubKeys)
+}
+
+func (c *NostrClient) knownProfiles(pubKeys []string) map[string]model.Profile {
+	result := make(map[string]model.Profile, len(pubKeys))
+	for _, pk := range pubKeys {
+		if p, ok := c.profiles.get(pk); ok && p.displayName() != "" {
+			result[pk] = c.profileFor(pk)
+		}
+	}
+	return result
+}
+
+// Refresh author names on results that may have been cached before profiles arrived.
+func (c *NostrClient) applyPostNames(posts model.Posts) model.Posts {
+	updated := make([]model.Post, len(posts.Posts))
+	for i, post := range posts.Posts {
+		profile := c.profileFor(post.PubKey)
+		post.Author, post.Verified = profile.Name, profile.Verified
+		updated[i] = post
+	}
+	posts.Posts = updated
+	return posts
+}
+
+func (c *NostrClient) applyCommentNames(comments model.Comments) model.Comments {
+	updated := make([]model.Comment, len(comments.Comments))
+	for i, comment := range comments.Comments {
+		profile := c.profileFor(comment.PubKey)
+		comment.Author, comment.Verified = profile.Name, profile.Verified
+		updated[i] = comment
+	}
+	comments.Comments = updated
+	if comments.PostPubKey != "" {
+		profile := c.profileFor(comments.PostPubKey)
+		comments.PostAuthor, comments.PostVerified = profile.Name, profile.Verified
+	}
+	return comments
+}
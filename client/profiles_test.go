@@ -0,0 +1,61 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestParseProfilePrefersDisplayName(t *testing.T) {
+	p, ok := parseProfile(nostr.Event{Content: `{"name":"alice","display_name":"Alice A."}`})
+	if !ok {
+		t.Fatalf("expected profile to parse")
+	}
+	if p.displayName() != "Alice A." {
+		t.Fatalf("expected display name, got %s", p.displayName())
+	}
+
+	if _, ok := parseProfile(nostr.Event{Content: "not json"}); ok {
+		t.Fatalf("expected invalid metadata to be rejected")
+	}
+}
+
+func TestParseProfileSanitizesNames(t *testing.T) {
+	p, ok := parseProfile(nostr.Event{Content: `{"name":"mallory\u001b[2J\n\tadmin","display_name":"Bob ✓ \u009b31m"}`})
+	if !ok {
+		t.Fatalf("expected profile to parse")
+	}
+	if p.Name != "mallory[2J admin" {
+		t.Fatalf("expected control characters stripped from name, got %q", p.Name)
+	}
+	if p.DisplayName != "Bob 31m" {
+		t.Fatalf("expected check mark and control characters stripped, got %q", p.DisplayName)
+	}
+
+	long, _ := parseProfile(nostr.Event{Content: `{"name":"` + strings.Repeat("界", 100) + `"}`})
+	if n := len([]rune(long.Name)); n != maxNameLength {
+		t.Fatalf("expected name clamped to %d runes, got %d", maxNameLength, n)
+	}
+}
+
+func TestProfileStoreKeepsNewest(t *testing.T) {
+	store := newProfileStore()
+	store.set("pk", profile{Name: "new", createdAt: 20})
+	store.set("pk", profile{Name: "old", createdAt: 10})
+
+	if p, ok := store.get("pk"); !ok || p.Name != "new" {
+		t.Fatalf("expected newest profile to win, got %+v ok=%v", p, ok)
+	}
+}
+
+func TestProfileStoreMissing(t *testing.T) {
+	store := newProfileStore()
+	store.set("known", profile{Name: "known"})
+	store.set("empty", profile{})
+
+	missing := store.missing([]string{"known", "empty", "new", "new", ""})
+	if len(missing) != 1 || missing[0] != "new" {
+		t.Fatalf("expected only unseen pubkeys, got %v", missing)
+	}
+}
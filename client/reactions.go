@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+	"tuistr/model"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	reactionTTL       = 5 * time.Minute
+	reactionBatchSize = 100
+)
+
+var ErrInvalidReaction = errors.New(`reaction must be "+" or "-"`)
+
+type vote struct {
+	value     int
+	createdAt nostr.Timestamp
+}
+
+// reactionStore tallies NIP-25 reactions per target event, counting only the
+// newest reaction of each author.
+type reactionStore struct {
+	mu      sync.Mutex
+	votes   map[string]map[string]vote
+	fetched *simpleCache[bool]
+}
+
+func newReactionStore() *reactionStore {
+	return &reactionStore{
+		votes:   make(map[string]map[string]vote),
+		fetched: newSimpleCache[bool](),
+	}
+}
+
+func (s *reactionStore) add(evt nostr.Event) {
+	target := reactionTarget(evt.Tags)
+	if target == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	votes, ok := s.votes[target]
+	if !ok {
+		votes = make(map[string]vote)
+		s.votes[target] = votes
+	}
+
+	if existing, ok := votes[evt.PubKey]; ok && existing.createdAt > evt.CreatedAt {
+		return
+	}
+	votes[evt.PubKey] = vote{value: reactionValue(evt.Content), createdAt: evt.CreatedAt}
+}
+
+// score sums the votes on ids, which are versions of the same post. Someone who
+// reacted to more than one version only counts with their newest vote.
+func (s *reactionStore) score(ids ...string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	newest := make(map[string]vote)
+	for _, id := range ids {
+		for pubKey, v := range s.votes[id] {
+			if existing, ok := newest[pubKey]; !ok || v.createdAt > existing.createdAt {
+				newest[pubKey] = v
+			}
+		}
+	}
+
+	score := 0
+	for _, v := range newest {
+		score += v.value
+	}
+	return score
+}
+
+// NIP-25: the last e tag is the event being reacted to.
+func reactionTarget(tags nostr.Tags) string {
+	target := ""
+	for _, tag := range tags {
+		if len(tag) >= 2 && tag[0] == "e" {
+			target = tag[1]
+		}
+	}
+	return target
+}
+
+// "-" is a downvote; "+", an empty content and emoji all count as likes.
+func reactionValue(content string) int {
+	if content == "-" {
+		return -1
+	}
+	return 1
+}
+
+// FetchScores returns the reaction score for each event id, asking relays only for
+// ids that haven't been tallied recently.
+func (c *NostrClient) FetchScores(ids []string) map[string]int {
+	c.fetchReactions(ids)
+
+	scores := make(map[string]int, len(ids))
+	for _, id := range ids {
+		scores[id] = c.reactions.score(id)
+	}
+	return scores
+}
+
+// FetchPostScores returns each post's score by id, counting reactions to every
+// version of an edited post.
+func (c *NostrClient) FetchPostScores(posts []model.Post) map[string]int {
+	var ids []string
+	for _, post := range posts {
+		ids = append(ids, post.EventIDs()...)
+	}
+	c.fetchReactions(ids)
+
+	scores := make(map[string]int, len(posts))
+	for _, post := range posts {
+		scores[post.ID] = c.reactions.score(post.EventIDs()...)
+	}
+	return scores
+}
+
+func (c *NostrClient) fetchReactions(ids []string) {
+	var missing []string
+	for _, id := range ids {
+		if _, ok := c.reactions.fetched.get(id); !ok && isValidEventID(id) {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+
+		for start := 0; start < len(missing); start += reactionBatchSize {
+			batch := missing[start:min(start+reactionBatchSize, len(missing))]
+			for _, evt := range c.collect(ctx, nostr.Filter{Kinds: []int{7}, Tags: nostr.TagMap{"e": batch}}) {
+				c.reactions.add(evt)
+			}
+			for _, id := range batch {
+				c.reactions.fetched.set(id, true, time.Now().Add(reactionTTL))
+			}
+		}
+	}
+}
+
+// React publishes a kind 7 reaction ("+" or "-") to an event and returns the
+// event's updated score. For an edited post, versions lists its earlier versions
+// so the score counts them too.
+func (c *NostrClient) React(eventID, authorPubKey string, kind int, content string, versions []string) (int, error) {
+	if content != "+" && content != "-" {
+		return 0, ErrInvalidReaction
+	}
+	if !isValidEventID(eventID) {
+		return 0, ErrInvalidThreadID
+	}
+
+	tags := nostr.Tags{eventTag("e", eventID, authorPubKey)}
+	if authorPubKey != "" {
+		tags = append(tags, nostr.Tag{"p", authorPubKey})
+	}
+	if kind != 0 {
+		tags = append(tags, nostr.Tag{"k", strconv.Itoa(kind)})
+	}
+
+	evt := nostr.Event{
+		Kind:    7,
+		Tags:    tags,
+		Content: content,
+	}
+
+	if err := c.signAndPublish(&evt, authorPubKey); err != nil {
+		return 0, err
+	}
+
+	c.reactions.add(evt)
+	return c.reactions.score(append([]string{eventID}, versions...)...), nil
+}
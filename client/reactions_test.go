@@ -0,0 +1,46 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestReactionStoreCountsNewestVotePerAuthor(t *testing.T) {
+	store := newReactionStore()
+	target := "target"
+
+	store.add(nostr.Event{PubKey: "alice", CreatedAt: 1, Content: "+", Tags: nostr.Tags{{"e", "root"}, {"e", target}}})
+	store.add(nostr.Event{PubKey: "bob", CreatedAt: 1, Content: "+", Tags: nostr.Tags{{"e", target}}})
+	store.add(nostr.Event{PubKey: "bob", CreatedAt: 2, Content: "-", Tags: nostr.Tags{{"e", target}}})
+	store.add(nostr.Event{PubKey: "carol", CreatedAt: 1, Content: "🤙", Tags: nostr.Tags{{"e", target}}})
+
+	if score := store.score(target); score != 1 {
+		t.Fatalf("expected score 1, got %d", score)
+	}
+	if score := store.score("root"); score != 0 {
+		t.Fatalf("expected reactions to count only for the last e tag, got %d", score)
+	}
+}
+
+func TestReactionStoreIgnoresOlderVote(t *testing.T) {
+	store := newReactionStore()
+	store.add(nostr.Event{PubKey: "alice", CreatedAt: 5, Content: "-", Tags: nostr.Tags{{"e", "x"}}})
+	store.add(nostr.Event{PubKey: "alice", CreatedAt: 1, Content: "+", Tags: nostr.Tags{{"e", "x"}}})
+
+	if score := store.score("x"); score != -1 {
+		t.Fatalf("expected newest downvote to stand, got %d", score)
+	}
+}
+
+func TestReactionStoreCombinesPostVersions(t *testing.T) {
+	store := newReactionStore()
+	store.add(nostr.Event{PubKey: "alice", CreatedAt: 1, Content: "+", Tags: nostr.Tags{{"e", "original"}}})
+	store.add(nostr.Event{PubKey: "alice", CreatedAt: 2, Content: "+", Tags: nostr.Tags{{"e", "revision"}}})
+	store.add(nostr.Event{PubKey: "bob", CreatedAt: 1, Content: "+", Tags: nostr.Tags{{"e", "original"}}})
+	store.add(nostr.Event{PubKey: "carol", CreatedAt: 3, Content: "-", Tags: nostr.Tags{{"e", "revision"}}})
+
+	if score := store.score("revision", "original"); score != 1 {
+		t.Fatalf("expected one vote per author across versions, got %d", score)
+	}
+}
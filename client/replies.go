@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip11"
+)
+
+const (
+	replyCountTTL    = 5 * time.Minute
+	replyBatchSize   = 50
+	replyFetchLimit  = 500
+	countProbeTime   = 3 * time.Second
+	countConcurrency = 8
+	countSupportTTL  = time.Hour
+)
+
+// replyKinds are the kinds that count as replies: NIP-22 comments and legacy
+// kind 1 notes.
+var replyKinds = []int{1, 1111}
+
+// FetchReplyCounts returns how many comments each thread root has. Relays that
+// list NIP-45 in their information document are asked to COUNT, so the replies
+// themselves aren't downloaded; the rest, and any relay whose COUNT fails, are
+// fetched from and the replies tallied. Each relay only knows about the events
+// it holds, so each thread keeps the highest count any source reported.
+func (c *NostrClient) FetchReplyCounts(ids []string) map[string]int {
+	counts := make(map[string]int, len(ids))
+
+	var missing []string
+	for _, id := range ids {
+		if count, ok := c.replyCounts.get(id); ok {
+			counts[id] = count
+		} else if isValidEventID(id) {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) == 0 {
+		return counts
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counted, fallback := c.countReplies(ctx, missing)
+
+	if len(fallback) > 0 || c.offline {
+		for start := 0; start < len(missing); start += replyBatchSize {
+			batch := missing[start:min(start+replyBatchSize, len(missing))]
+
+			var events []nostr.Event
+			for _, tag := range []string{"e", "E"} {
+				filter := nostr.Filter{Kinds: replyKinds, Tags: nostr.TagMap{tag: batch}, Limit: replyFetchLimit}
+				events = append(events, c.collectFrom(ctx, fallback, filter)...)
+			}
+			for id, count := range tallyReplies(events, batch) {
+				counted[id] = max(counted[id], count)
+			}
+		}
+	}
+
+	for _, id := range missing {
+		counts[id] = counted[id]
+		c.replyCounts.set(id, counted[id], time.Now().Add(replyCountTTL))
+	}
+
+	return counts
+}
+
+// countReplies asks every relay that supports NIP-45 to COUNT the replies to
+// each thread, keeping the highest answer per thread. It returns the relays that
+// couldn't count, which have to be fetched from instead.
+func (c *NostrClient) countReplies(ctx context.Context, ids []string) (map[string]int, []string) {
+	counts := make(map[string]int, len(ids))
+	if c.offline || len(ids) == 0 {
+		return counts, nil
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		fallback []string
+	)
+
+	for _, url := range c.activeRelays() {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+
+			if !c.supportsCount(ctx, url) || !c.countOn(ctx, url, ids, func(id string, n int) {
+				mu.Lock()
+				defer mu.Unlock()
+				counts[id] = max(counts[id], n)
+			}) {
+				mu.Lock()
+				fallback = append(fallback, url)
+				mu.Unlock()
+			}
+		}(url)
+	}
+
+	wg.Wait()
+	return counts, fallback
+}
+
+// countOn asks the relay at url to COUNT the replies to each id, passing every
+// answer to record. It reports false if the relay couldn't count, in which case
+// it isn't asked again for a while.
+func (c *NostrClient) countOn(ctx context.Context, url string, ids []string, record func(id string, n int)) bool {
+	relay, err := c.pool.EnsureRelay(url)
+	if err != nil {
+		return false
+	}
+
+	// Direct replies tag the thread as their parent and nested ones add it as a
+	// root-marked e tag, so one filter finds both
+	count := func(ctx context.Context, id string) (int, bool) {
+		filter := nostr.Filter{Kinds: replyKinds, Tags: nostr.TagMap{"e": []string{id}}}
+		n, _, err := relay.Count(ctx, nostr.Filters{filter})
+		return int(n), err == nil
+	}
+
+	// Probe with the first id so a relay that advertises COUNT but ignores it
+	// doesn't hold us up
+	probeCtx, cancel := context.WithTimeout(ctx, countProbeTime)
+	n, ok := count(probeCtx, ids[0])
+	cancel()
+	if !ok {
+		c.countSupport.set(url, false, time.Now().Add(countSupportTTL))
+		return false
+	}
+	record(ids[0], n)
+
+	sem := make(chan struct{}, countConcurrency)
+	var wg sync.WaitGroup
+	for _, id := range ids[1:] {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if n, ok := count(ctx, id); ok {
+				record(id, n)
+			}
+		}(id)
+	}
+	wg.Wait()
+	return true
+}
+
+// supportsCount reports whether the relay at url lists NIP-45 in its NIP-11
+// information document. Answers are cached, including failed lookups.
+func (c *NostrClient) supportsCount(ctx context.Context, url string) bool {
+	if supported, ok := c.countSupport.get(url); ok {
+		return supported
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, countProbeTime)
+	defer cancel()
+
+	supported := false
+	if doc, err := nip11.Fetch(lookupCtx, url); err == nil {
+		supported = slices.Contains(relayInfoFromDocument(doc).SupportedNIPs, 45)
+	}
+	c.countSupport.set(url, supported, time.Now().Add(countSupportTTL))
+	return supported
+}
+
+// tallyReplies counts comments per thread root among ids. Comments rooted at the
+// thread name it in their E tag; ours are rooted at the community, so direct
+// replies tag the thread as their parent and nested ones add a root-marked e tag.
+func tallyReplies(events []nostr.Event, ids []string) map[string]int {
+	counts := make(map[string]int, len(ids))
+	for _, id := range ids {
+		counts[id] = 0
+	}
+
+	seen := make(map[string]bool, len(events))
+	for _, evt := range events {
+		if seen[evt.ID] {
+			continue
+		}
+		seen[evt.ID] = true
+
+		var root string
+		for _, tag := range evt.Tags {
+			if len(tag) < 2 || (tag[0] != "e" && tag[0] != "E") {
+				continue
+			}
+			if _, ok := counts[tag[1]]; ok {
+				root = tag[1]
+				break
+			}
+		}
+		if _, ok := counts[root]; ok && root != evt.ID {
+			counts[root]++
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"testing"
+	"tuistr/config"
+	"tuistr/model"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestTallyRepliesCountsByRootTag(t *testing.T) {
+	events := []nostr.Event{
+		{ID: "c1", Tags: nostr.Tags{{"E", "root1"}, {"e", "root1"}}},
+		{ID: "c2", Tags: nostr.Tags{{"E", "root1"}, {"e", "c1"}}},
+		{ID: "c2", Tags: nostr.Tags{{"E", "root1"}, {"e", "c1"}}},
+		{ID: "c3", Tags: nostr.Tags{{"E", "root2"}}},
+		{ID: "c4", Tags: nostr.Tags{{"E", "other"}}},
+		{ID: "c5", Tags: nostr.Tags{{"e", "root1"}}},
+		{ID: "c6", Tags: nostr.Tags{{"I", "t:nostr"}, {"e", "c5"}, {"e", "root2", "", "root"}}},
+	}
+
+	counts := tallyReplies(events, []string{"root1", "root2", "root3"})
+
+	want := map[string]int{"root1": 3, "root2": 2, "root3": 0}
+	if len(counts) != len(want) {
+		t.Fatalf("expected %d counts, got %v", len(want), counts)
+	}
+	for id, n := range want {
+		if counts[id] != n {
+			t.Errorf("%s: expected %d replies, got %d", id, n, counts[id])
+		}
+	}
+}
+
+func TestFetchReplyCountsBatchesThreads(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.NewConfig()
+	cfg.Nostr.Offline = true
+	c, err := NewNostrClient(cfg)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer c.Close()
+
+	author := nostr.GeneratePrivateKey()
+	busy := signedEvent(t, author, nostr.Event{Kind: 1111, Tags: communityTags("t:nostr"), Content: "busy"})
+	quiet := signedEvent(t, author, nostr.Event{Kind: 1111, Tags: communityTags("t:nostr"), Content: "quiet"})
+	c.store.save(busy, quiet)
+	for _, text := range []string{"one", "two"} {
+		c.store.save(signedEvent(t, author, nostr.Event{Kind: 1111, Tags: replyTags(c.eventToPost(busy), model.Comment{}), Content: text}))
+	}
+
+	// a legacy kind 1 reply counts too
+	c.store.save(signedEvent(t, author, nostr.Event{Kind: 1, Tags: nostr.Tags{{"e", busy.ID, "", "root"}}, Content: "three"}))
+
+	counts := c.FetchReplyCounts([]string{busy.ID, quiet.ID, "not an id"})
+	if counts[busy.ID] != 3 || counts[quiet.ID] != 0 {
+		t.Fatalf("expected 3 and 0 replies, got %v", counts)
+	}
+	if _, ok := counts["not an id"]; ok {
+		t.Fatalf("expected invalid ids to be skipped, got %v", counts)
+	}
+}
@@ -0,0 +1,352 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"tuistr/utils"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip46"
+	"github.com/nbd-wtf/go-nostr/nip49"
+)
+
+const (
+	bunkerClientKeyFilename = "nip46-client.key"
+	// bunkerTimeout leaves time to approve a request in the signer app
+	bunkerTimeout = 60 * time.Second
+)
+
+var (
+	ErrInvalidBunkerURL = errors.New("bunker url must look like bunker://<pubkey>?relay=wss://...")
+	ErrSignerLocked     = errors.New("secret key is encrypted; enter its passphrase first")
+	ErrWrongPassphrase  = errors.New("wrong passphrase")
+)
+
+// Signer signs events for the user. The client only ever sees the signed event,
+// so the secret key can live somewhere else entirely.
+type Signer interface {
+	// PublicKey returns the user's public key, or "" while it isn't known yet.
+	PublicKey() string
+	// SignEvent fills in the event's pubkey, id and signature.
+	SignEvent(ctx context.Context, evt *nostr.Event) error
+	// Close releases any connection the signer holds.
+	Close()
+}
+
+// localSigner signs with a secret key held in memory.
+type localSigner struct {
+	privKey string
+	pubKey  string
+}
+
+func newLocalSigner(secret string) (*localSigner, error) {
+	privKey, pubKey, err := parsePrivKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &localSigner{privKey: privKey, pubKey: pubKey}, nil
+}
+
+func (s *localSigner) PublicKey() string {
+	return s.pubKey
+}
+
+func (s *localSigner) SignEvent(_ context.Context, evt *nostr.Event) error {
+	evt.PubKey = s.pubKey
+	return evt.Sign(s.privKey)
+}
+
+func (s *localSigner) Close() {}
+
+// encryptedSigner holds a NIP-49 ncryptsec and only signs once it has been
+// unlocked with the passphrase. The decrypted key stays in memory for the session.
+type encryptedSigner struct {
+	ncryptsec string
+
+	mu    sync.Mutex
+	local *localSigner
+}
+
+func newEncryptedSigner(ncryptsec string) (*encryptedSigner, error) {
+	ncryptsec = strings.TrimSpace(ncryptsec)
+	if !isNcryptsec(ncryptsec) {
+		return nil, errors.New("encrypted key must be an ncryptsec")
+	}
+	return &encryptedSigner{ncryptsec: ncryptsec}, nil
+}
+
+func isNcryptsec(secret string) bool {
+	return strings.HasPrefix(strings.TrimSpace(secret), "ncryptsec1")
+}
+
+func (s *encryptedSigner) Locked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.local == nil
+}
+
+// Unlock decrypts the key with passphrase.
+func (s *encryptedSigner) Unlock(passphrase string) error {
+	privKey, err := nip49.Decrypt(s.ncryptsec, passphrase)
+	if err != nil {
+		// a bad passphrase derives the wrong key, which fails the AEAD check
+		if strings.Contains(err.Error(), "authentication failed") {
+			return ErrWrongPassphrase
+		}
+		return err
+	}
+
+	local, err := newLocalSigner(privKey)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.local = local
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *encryptedSigner) PublicKey() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.local == nil {
+		return ""
+	}
+	return s.local.PublicKey()
+}
+
+func (s *encryptedSigner) SignEvent(ctx context.Context, evt *nostr.Event) error {
+	s.mu.Lock()
+	local := s.local
+	s.mu.Unlock()
+
+	if local == nil {
+		return ErrSignerLocked
+	}
+	return local.SignEvent(ctx, evt)
+}
+
+func (s *encryptedSigner) Close() {}
+
+// bunkerSigner asks a NIP-46 remote signer to sign events. It connects on first
+// use and keeps the connection for the session.
+type bunkerSigner struct {
+	target    string
+	relays    []string
+	secret    string
+	clientKey string
+
+	connectMu sync.Mutex
+	bunker    *nip46.BunkerClient
+
+	mu      sync.Mutex
+	pubKey  string
+	authURL string
+	pool    *nostr.SimplePool
+	stop    context.CancelFunc
+}
+
+// parseBunkerURL reads bunker://<remote-signer-pubkey>?relay=wss://...&secret=...
+func parseBunkerURL(uri string) (target string, relays []string, secret string, err error) {
+	parsed, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || parsed.Scheme != "bunker" {
+		return "", nil, "", ErrInvalidBunkerURL
+	}
+
+	target = parsed.Host
+	if !nostr.IsValidPublicKey(target) {
+		return "", nil, "", fmt.Errorf("%w: %q is not a hex public key", ErrInvalidBunkerURL, target)
+	}
+
+	for _, relay := range parsed.Query()["relay"] {
+		if isRelayURL(relay) {
+			relays = append(relays, nostr.NormalizeURL(relay))
+		}
+	}
+	if len(relays) == 0 {
+		return "", nil, "", fmt.Errorf("%w: no relay given", ErrInvalidBunkerURL)
+	}
+
+	return target, relays, parsed.Query().Get("secret"), nil
+}
+
+func newBunkerSigner(uri, clientKey string) (*bunkerSigner, error) {
+	target, relays, secret, err := parseBunkerURL(uri)
+	if err != nil {
+		return nil, err
+	}
+	return &bunkerSigner{target: target, relays: relays, secret: secret, clientKey: clientKey}, nil
+}
+
+func (s *bunkerSigner) PublicKey() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pubKey
+}
+
+// connect opens the NIP-46 session and learns the user's public key.
+func (s *bunkerSigner) connect(ctx context.Context) error {
+	s.connectMu.Lock()
+	defer s.connectMu.Unlock()
+
+	if s.PublicKey() != "" {
+		return nil
+	}
+
+	if s.bunker == nil {
+		// the bunker's own pool and subscription live until Close
+		ctx, stop := context.WithCancel(context.Background())
+		pool := nostr.NewSimplePool(ctx)
+		s.bunker = nip46.NewBunker(ctx, s.clientKey, s.target, s.relays, pool, s.onAuth)
+
+		s.mu.Lock()
+		s.pool, s.stop = pool, stop
+		s.mu.Unlock()
+	}
+
+	// a bunker that already knows our client key may reject the one-time secret
+	// again, so only give up if it won't tell us the public key either
+	_, connectErr := s.bunker.RPC(ctx, "connect", []string{s.target, s.secret})
+	pubKey, err := s.bunker.GetPublicKey(ctx)
+	if err != nil || !nostr.IsValidPublicKey(pubKey) {
+		return s.wrap(errors.Join(connectErr, err))
+	}
+
+	s.mu.Lock()
+	s.pubKey = pubKey
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *bunkerSigner) SignEvent(ctx context.Context, evt *nostr.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, bunkerTimeout)
+	defer cancel()
+
+	if err := s.connect(ctx); err != nil {
+		return err
+	}
+
+	evt.PubKey = s.PublicKey()
+	if err := s.bunker.SignEvent(ctx, evt); err != nil {
+		return s.wrap(err)
+	}
+	if evt.PubKey != s.PublicKey() {
+		return errors.New("bunker signed with a different key")
+	}
+	return nil
+}
+
+// Close ends the bunker subscription and disconnects from its relays.
+func (s *bunkerSigner) Close() {
+	s.mu.Lock()
+	pool, stop := s.pool, s.stop
+	s.pool, s.stop = nil, nil
+	s.mu.Unlock()
+
+	if stop != nil {
+		stop()
+		pool.Close("signer closed")
+	}
+}
+
+// onAuth is called when the bunker wants the user to approve us on a web page.
+func (s *bunkerSigner) onAuth(authURL string) {
+	slog.Warn("Remote signer asks for approval", "url", authURL)
+	s.mu.Lock()
+	s.authURL = authURL
+	s.mu.Unlock()
+}
+
+func (s *bunkerSigner) wrap(err error) error {
+	if err == nil {
+		err = errors.New("no response")
+	}
+
+	s.mu.Lock()
+	authURL := s.authURL
+	s.mu.Unlock()
+
+	if authURL != "" {
+		return fmt.Errorf("remote signer: %w (approve this client at %s)", err, authURL)
+	}
+	return fmt.Errorf("remote signer: %w", err)
+}
+
+// newSigner picks the signer from the config: a bunker if one is set, otherwise
+// the secret key, plain or NIP-49 encrypted. It returns nil when neither is
+// configured.
+func newSigner(bunker, secretKey string) (Signer, error) {
+	if strings.TrimSpace(bunker) != "" {
+		clientKey, err := loadBunkerClientKey()
+		if err != nil {
+			return nil, fmt.Errorf("could not load bunker client key: %w", err)
+		}
+		signer, err := newBunkerSigner(bunker, clientKey)
+		if err != nil {
+			return nil, err
+		}
+		return signer, nil
+	}
+
+	if isNcryptsec(secretKey) {
+		signer, err := newEncryptedSigner(secretKey)
+		if err != nil {
+			return nil, err
+		}
+		return signer, nil
+	}
+
+	if strings.TrimSpace(secretKey) != "" {
+		signer, err := newLocalSigner(secretKey)
+		if err != nil {
+			return nil, err
+		}
+		return signer, nil
+	}
+
+	return nil, nil
+}
+
+// loadBunkerClientKey returns the throwaway key we talk to the bunker with,
+// creating it on first use. Keeping it lets the bunker remember its approval.
+func loadBunkerClientKey() (string, error) {
+	configDir, err := utils.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return readOrCreateKey(filepath.Join(configDir, bunkerClientKeyFilename))
+}
+
+func readOrCreateKey(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key := strings.TrimSpace(string(data))
+		if _, decodeErr := hex.DecodeString(key); decodeErr == nil && len(key) == 64 {
+			return key, nil
+		}
+		return "", fmt.Errorf("%s does not hold a hex key", path)
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	key := nostr.GeneratePrivateKey()
+	if err := os.WriteFile(path, []byte(key+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return key, nil
+}
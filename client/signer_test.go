@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip49"
+)
+
+func TestParseBunkerURL(t *testing.T) {
+	pubKey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	target, relays, secret, err := parseBunkerURL("bunker://" + pubKey + "?relay=wss://relay.example.com/&relay=https://not-a-relay.example.com&secret=s3cret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != pubKey || secret != "s3cret" || !slices.Equal(relays, []string{"wss://relay.example.com"}) {
+		t.Fatalf("unexpected parse result %q %v %q", target, relays, secret)
+	}
+
+	for _, uri := range []string{
+		"nostrconnect://" + pubKey + "?relay=wss://relay.example.com",
+		"bunker://npub1notahexkey?relay=wss://relay.example.com",
+		"bunker://" + pubKey,
+	} {
+		if _, _, _, err := parseBunkerURL(uri); !errors.Is(err, ErrInvalidBunkerURL) {
+			t.Fatalf("expected %q to be rejected, got %v", uri, err)
+		}
+	}
+}
+
+func TestLocalSignerSignsAsItsKey(t *testing.T) {
+	privKey := nostr.GeneratePrivateKey()
+	signer, err := newSigner("", privKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	evt := nostr.Event{Kind: 1111, Content: "hello", CreatedAt: nostr.Now()}
+	if err := signer.SignEvent(context.Background(), &evt); err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+	if ok, _ := evt.CheckSignature(); !ok || evt.PubKey != signer.PublicKey() {
+		t.Fatalf("expected a valid signature from %s, got %+v", signer.PublicKey(), evt)
+	}
+
+	if signer, err := newSigner("", ""); signer != nil || err != nil {
+		t.Fatalf("expected no signer without a key, got %v %v", signer, err)
+	}
+}
+
+func TestReadOrCreateKeyIsPrivateAndStable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuistr", bunkerClientKeyFilename)
+
+	key, err := readOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected a 0600 key file, got %v %v", info.Mode(), err)
+	}
+
+	again, err := readOrCreateKey(path)
+	if err != nil || again != key {
+		t.Fatalf("expected the same key on the next run, got %q %v", again, err)
+	}
+}
+
+func TestEncryptedSignerUnlocks(t *testing.T) {
+	privKey := nostr.GeneratePrivateKey()
+	ncryptsec, err := nip49.Encrypt(privKey, "correct horse", 4, nip49.ClientDoesNotTrackThisData)
+	if err != nil {
+		t.Fatalf("could not encrypt test key: %v", err)
+	}
+
+	c := &NostrClient{}
+	if c.signer, err = newSigner("", ncryptsec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.SignerLocked() || c.PubKey() != "" {
+		t.Fatalf("expected the key to start locked")
+	}
+
+	evt := nostr.Event{Kind: 1111, Content: "hello", CreatedAt: nostr.Now()}
+	if err := c.signer.SignEvent(context.Background(), &evt); !errors.Is(err, ErrSignerLocked) {
+		t.Fatalf("expected locked error, got %v", err)
+	}
+
+	if err := c.UnlockSigner("battery staple"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected wrong passphrase error, got %v", err)
+	}
+	if err := c.UnlockSigner("correct horse"); err != nil {
+		t.Fatalf("unlock failed: %v", err)
+	}
+
+	pubKey, _ := nostr.GetPublicKey(privKey)
+	if c.SignerLocked() || c.PubKey() != pubKey {
+		t.Fatalf("expected unlocked signer for %s, got %q", pubKey, c.PubKey())
+	}
+	if err := c.signer.SignEvent(context.Background(), &evt); err != nil || evt.PubKey != pubKey {
+		t.Fatalf("expected signing to work once unlocked, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	eventStoreFilename = "events.jsonl"
+	maxStoredEvents    = 50000
+)
+
+// storedKinds are the kinds worth keeping for offline browsing.
+var storedKinds = map[int]bool{0: true, 1: true, 5: true, 7: true, 1111: true, 10002: true}
+
+// eventStore is an append-only log of events on disk with an in-memory index. It
+// is loaded once at startup and compacted when it grows past maxStoredEvents.
+// Clients that share it each hold a reference; the file closes with the last one.
+type eventStore struct {
+	mu     sync.RWMutex
+	path   string
+	file   *os.File
+	events map[string]nostr.Event
+	refs   int
+}
+
+func openEventStore(path string) (*eventStore, error) {
+	store := &eventStore{
+		path:   path,
+		events: make(map[string]nostr.Event),
+		refs:   1,
+	}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	if len(store.events) > maxStoredEvents {
+		if err := store.compact(); err != nil {
+			slog.Warn("Could not compact event store", "path", path, "error", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	store.file = file
+
+	// Terminate a partial last line so the next append starts cleanly
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			file.Write([]byte{'\n'})
+		}
+	}
+
+	return store, nil
+}
+
+func (s *eventStore) load() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var evt nostr.Event
+		if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+			// A crash can leave a partial last line; skip it
+			continue
+		}
+		s.events[evt.ID] = evt
+	}
+
+	return scanner.Err()
+}
+
+// Rewrite the log with only the newest maxStoredEvents events.
+func (s *eventStore) compact() error {
+	events := make([]nostr.Event, 0, len(s.events))
+	for _, evt := range s.events {
+		events = append(events, evt)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt > events[j].CreatedAt
+	})
+	events = events[:min(len(events), maxStoredEvents)]
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(tmp)
+	s.events = make(map[string]nostr.Event, len(events))
+	for _, evt := range events {
+		line, err := json.Marshal(evt)
+		if err != nil {
+			continue
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+		s.events[evt.ID] = evt
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, s.path)
+}
+
+// save appends events that are new to the store. Kinds we don't browse are ignored.
+func (s *eventStore) save(events ...nostr.Event) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, evt := range events {
+		if !storedKinds[evt.Kind] {
+			continue
+		}
+		if _, ok := s.events[evt.ID]; ok {
+			continue
+		}
+
+		line, err := json.Marshal(evt)
+		if err != nil {
+			continue
+		}
+		if _, err := s.file.Write(append(line, '\n')); err != nil {
+			slog.Warn("Could not write to event store", "error", err)
+			return
+		}
+		s.events[evt.ID] = evt
+	}
+}
+
+// query returns stored events matching filter, newest first, honoring its limit.
+func (s *eventStore) query(filter nostr.Filter) []nostr.Event {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []nostr.Event
+	for _, evt := range s.events {
+		if filter.Matches(&evt) {
+			results = append(results, evt)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt > results[j].CreatedAt
+	})
+
+	if filter.Limit > 0 && len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+
+	return results
+}
+
+// retain adds a reference for another client using the store. A store that
+// was already closed can't be shared and returns nil.
+func (s *eventStore) retain() *eventStore {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refs == 0 {
+		return nil
+	}
+	s.refs++
+	return s
+}
+
+func (s *eventStore) close() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refs--; s.refs == 0 {
+		s.file.Close()
+	}
+}
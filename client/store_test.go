@@ -0,0 +1,117 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestEventStorePersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), eventStoreFilename)
+
+	store, err := openEventStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	store.save(
+		nostr.Event{ID: "post", Kind: 1111, CreatedAt: 10, Tags: nostr.Tags{{"I", "t:nostr"}}},
+		nostr.Event{ID: "other", Kind: 1111, CreatedAt: 20, Tags: nostr.Tags{{"I", "t:linux"}}},
+		nostr.Event{ID: "repost", Kind: 6, CreatedAt: 30},
+	)
+	store.close()
+
+	reopened, err := openEventStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error reopening: %v", err)
+	}
+	defer reopened.close()
+
+	events := reopened.query(nostr.Filter{Kinds: []int{1111}, Tags: nostr.TagMap{"I": {"t:nostr"}}})
+	if len(events) != 1 || events[0].ID != "post" {
+		t.Fatalf("expected stored community post, got %v", events)
+	}
+
+	if events := reopened.query(nostr.Filter{Kinds: []int{6}}); len(events) != 0 {
+		t.Fatalf("expected unsupported kinds to be skipped, got %v", events)
+	}
+}
+
+func TestEventStoreQueryOrdersAndLimits(t *testing.T) {
+	store, err := openEventStore(filepath.Join(t.TempDir(), eventStoreFilename))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.close()
+
+	store.save(
+		nostr.Event{ID: "a", Kind: 1111, CreatedAt: 1},
+		nostr.Event{ID: "b", Kind: 1111, CreatedAt: 3},
+		nostr.Event{ID: "c", Kind: 1111, CreatedAt: 2},
+	)
+
+	events := store.query(nostr.Filter{Kinds: []int{1111}, Limit: 2})
+	if len(events) != 2 || events[0].ID != "b" || events[1].ID != "c" {
+		t.Fatalf("expected newest two events, got %v", events)
+	}
+}
+
+func TestEventStoreSkipsTruncatedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), eventStoreFilename)
+	if err := os.WriteFile(path, []byte(`{"id":"ok","kind":1111,"created_at":1}`+"\n"+`{"id":"bro`), 0600); err != nil {
+		t.Fatalf("could not seed store: %v", err)
+	}
+
+	store, err := openEventStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if events := store.query(nostr.Filter{}); len(events) != 1 {
+		t.Fatalf("expected the intact event only, got %v", events)
+	}
+
+	store.save(nostr.Event{ID: "next", Kind: 1111, CreatedAt: 2})
+	store.close()
+
+	reopened, err := openEventStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error reopening: %v", err)
+	}
+	defer reopened.close()
+
+	if events := reopened.query(nostr.Filter{}); len(events) != 2 {
+		t.Fatalf("expected events appended after a partial line to survive, got %v", events)
+	}
+}
+
+func TestEventStoreStaysOpenWhileShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), eventStoreFilename)
+
+	store, err := openEventStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	shared := store.retain()
+	store.close()
+
+	shared.save(nostr.Event{ID: "post", Kind: 1111, CreatedAt: 10})
+	shared.close()
+
+	if store.retain() != nil {
+		t.Fatalf("expected a closed store not to be shared again")
+	}
+
+	reopened, err := openEventStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error reopening: %v", err)
+	}
+	defer reopened.close()
+
+	if events := reopened.query(nostr.Filter{IDs: []string{"post"}}); len(events) != 1 {
+		t.Fatalf("expected the event saved after the first close to be kept, got %v", events)
+	}
+}
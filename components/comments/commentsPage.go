@@ -1,7 +1,10 @@
 package comments
 
 import (
+	"context"
 	"log/slog"
+	"maps"
+	"time"
 	"tuistr/client"
 	"tuistr/components/messages"
 	"tuistr/components/styles"
@@ -20,6 +23,9 @@ type CommentsPage struct {
 	containerStyle lipgloss.Style
 	postUrl        string
 	currentPost    model.Post
+	live           <-chan model.Comment
+	stopLive       context.CancelFunc
+	liveSince      time.Time
 	focus          bool
 }
 
@@ -59,10 +65,61 @@ func (c CommentsPage) handleGlobalMessages(msg tea.Msg) (CommentsPage, tea.Cmd)
 	case messages.LoadThreadMsg:
 		post := model.Post(msg)
 		c.currentPost = post
+		c.liveSince = time.Now()
 		return c, c.loadThread(post)
 	case messages.UpdateCommentsMsg:
-		c.updateComments(model.Comments(msg))
-		return c, messages.LoadingComplete
+		comments := model.Comments(msg)
+		c.updateComments(comments)
+		cmds := []tea.Cmd{messages.LoadingComplete, c.loadMeta(comments), c.startLive()}
+		if comments.Stale {
+			cmds = append(cmds, c.refreshThread(c.currentPost))
+		}
+		return c, tea.Batch(cmds...)
+
+	case messages.RefreshCommentsMsg:
+		comments := model.Comments(msg)
+		if comments.PostID == c.currentPost.ID {
+			c.pager.RefreshContent(comments)
+			return c, c.loadMeta(comments)
+		}
+
+	case messages.LiveCommentMsg:
+		if msg.Source != c.live {
+			return c, nil
+		}
+		c.pager.AppendComment(msg.Comment)
+		return c, tea.Batch(
+			messages.WaitForLiveComment(c.live),
+			c.loadProfiles(model.Comments{Comments: []model.Comment{msg.Comment}}),
+		)
+
+	case messages.UpdateProfilesMsg:
+		c.updateAuthors(msg)
+
+	case messages.UpdateScoresMsg:
+		if score, ok := msg[c.currentPost.ID]; ok {
+			c.currentPost.Score = score
+			c.header.Score = score
+		}
+		c.pager.SetScores(msg)
+
+	case messages.PostEditedMsg:
+		if msg.Original.ID != c.currentPost.ID {
+			return c, nil
+		}
+		// follow the thread to the new version
+		c.currentPost = msg.Revision
+		c.postUrl = msg.Revision.PostUrl
+		c.header.Description = msg.Revision.PostTitle
+		c.header.Edited = true
+		return c, tea.Batch(c.refreshThread(msg.Revision), c.startLive())
+
+	case messages.EventDeletedMsg:
+		if string(msg) == c.currentPost.ID {
+			// the feed has dropped it too, so there is nothing left to show here
+			return c, messages.GoBack
+		}
+		c.pager.MarkDeleted(string(msg))
 	}
 
 	return c, nil
@@ -77,9 +134,37 @@ func (c CommentsPage) handleFocusedMessages(msg tea.Msg) (CommentsPage, tea.Cmd)
 
 		case "r":
 			if c.currentPost.ID != "" {
-				return c, messages.ShowReplyModal(c.currentPost)
+				parent, _ := c.pager.SelectedComment()
+				return c, messages.ShowReplyModal(c.currentPost, parent)
 			}
 
+		case "+", "-":
+			if c.currentPost.ID == "" {
+				return c, nil
+			}
+			if comment, ok := c.pager.SelectedComment(); ok {
+				return c, messages.React(comment.ID, comment.PubKey, comment.Kind, keypress)
+			}
+			return c, messages.ReactToPost(c.currentPost, keypress)
+
+		case "e":
+			// replies can't be revised, so this always edits the post
+			if c.currentPost.ID != "" && !c.pager.postDeleted {
+				return c, messages.ShowEditModal(c.currentPost)
+			}
+
+		case "x":
+			if c.currentPost.ID == "" {
+				return c, nil
+			}
+			if comment, ok := c.pager.SelectedComment(); ok {
+				if comment.Deleted {
+					return c, nil
+				}
+				return c, messages.DeleteEvent(comment.ID, comment.PubKey, comment.Kind)
+			}
+			return c, messages.DeleteEvent(c.currentPost.ID, c.currentPost.PubKey, c.currentPost.Kind)
+
 		case "y":
 			if c.currentPost.ID != "" {
 				return c, func() tea.Msg {
@@ -134,6 +219,10 @@ func (c *CommentsPage) resizeComponents() {
 
 func (c *CommentsPage) loadThread(post model.Post) tea.Cmd {
 	return func() tea.Msg {
+		if comments, ok := c.nostrClient.GetStoredThread(post); ok {
+			return messages.UpdateCommentsMsg(comments)
+		}
+
 		comments, err := c.nostrClient.GetThread(post)
 		if err != nil {
 			slog.Error(commentsErrorText, "error", err)
@@ -144,6 +233,46 @@ func (c *CommentsPage) loadThread(post model.Post) tea.Cmd {
 	}
 }
 
+// Stream new replies into the open thread, replacing the previous subscription.
+// It starts at liveSince so replies published while the thread loaded aren't
+// missed.
+func (c *CommentsPage) startLive() tea.Cmd {
+	if c.stopLive != nil {
+		c.stopLive()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopLive = cancel
+	c.live = c.nostrClient.SubscribeThread(ctx, c.currentPost, c.liveSince)
+
+	return messages.WaitForLiveComment(c.live)
+}
+
+// StopLive closes the thread subscription once the page is left.
+func (c *CommentsPage) StopLive() {
+	if c.stopLive == nil {
+		return
+	}
+
+	c.stopLive()
+	c.stopLive = nil
+	c.live = nil
+	c.liveSince = time.Now()
+}
+
+// Fetch the thread from relays to replace comments served from the event store
+func (c *CommentsPage) refreshThread(post model.Post) tea.Cmd {
+	return func() tea.Msg {
+		comments, err := c.nostrClient.GetThread(post)
+		if err != nil {
+			slog.Warn("Could not refresh thread", "error", err)
+			return nil
+		}
+
+		return messages.RefreshCommentsMsg(comments)
+	}
+}
+
 func (c *CommentsPage) updateComments(comments model.Comments) {
 	c.header.SetContent(comments)
 	c.pager.SetContent(comments)
@@ -158,3 +287,48 @@ func (c *CommentsPage) updateComments(comments model.Comments) {
 	// Need to resize components when content loads so padding and margins are correct
 	c.resizeComponents()
 }
+
+func (c *CommentsPage) loadMeta(comments model.Comments) tea.Cmd {
+	return tea.Batch(c.loadProfiles(comments), c.loadScores(comments))
+}
+
+func (c *CommentsPage) loadScores(comments model.Comments) tea.Cmd {
+	ids := make([]string, 0, len(comments.Comments))
+	for _, comment := range comments.Comments {
+		ids = append(ids, comment.ID)
+	}
+
+	post := c.currentPost
+	return func() tea.Msg {
+		scores := c.nostrClient.FetchScores(ids)
+		// the post's score counts reactions to its earlier versions too
+		maps.Copy(scores, c.nostrClient.FetchPostScores([]model.Post{post}))
+		return messages.UpdateScoresMsg(scores)
+	}
+}
+
+func (c *CommentsPage) loadProfiles(comments model.Comments) tea.Cmd {
+	pubKeys := []string{comments.PostPubKey}
+	for _, comment := range comments.Comments {
+		pubKeys = append(pubKeys, comment.PubKey)
+	}
+
+	return tea.Sequence(
+		func() tea.Msg {
+			return messages.UpdateProfilesMsg(c.nostrClient.FetchProfiles(pubKeys))
+		},
+		func() tea.Msg {
+			return messages.UpdateProfilesMsg(c.nostrClient.VerifyProfiles(pubKeys))
+		},
+	)
+}
+
+func (c *CommentsPage) updateAuthors(profiles map[string]model.Profile) {
+	if profile, ok := profiles[c.currentPost.PubKey]; ok {
+		c.currentPost.Author = profile.Name
+		c.currentPost.Verified = profile.Verified
+		c.header.Author = profile.Name
+		c.header.Verified = profile.Verified
+	}
+	c.pager.SetAuthors(profiles)
+}
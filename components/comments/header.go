@@ -2,6 +2,7 @@ package comments
 
 import (
 	"fmt"
+	"strconv"
 	"tuistr/components/colors"
 	"tuistr/model"
 	"tuistr/utils"
@@ -28,7 +29,10 @@ type CommentsHeader struct {
 	Title            string
 	Description      string
 	Author           string
+	Verified         bool
+	Score            int
 	Timestamp        string
+	Edited           bool
 	Community        string
 	W                int
 }
@@ -46,7 +50,16 @@ func (h CommentsHeader) View() string {
 	titleView := titleStyle.Render(utils.TruncateString(h.Title, h.W))
 	descriptionView := h.DescriptionStyle.Render(h.Description)
 
-	meta := fmt.Sprintf("%s • %s", postAuthorStyle.Render(h.Author), postTimestampStyle.Render(h.Timestamp))
+	authorView := postAuthorStyle.Render(h.Author)
+	if h.Verified {
+		authorView = fmt.Sprintf("%s %s", authorView, verifiedStyle.Render("✓"))
+	}
+
+	scoreView := postTimestampStyle.Render(utils.GetSingularPlural(strconv.Itoa(h.Score), "point", "points"))
+	meta := fmt.Sprintf("%s • %s  %s", authorView, postTimestampStyle.Render(h.Timestamp), scoreView)
+	if h.Edited {
+		meta += postTimestampStyle.Render("  (edited)")
+	}
 	joinedView := lipgloss.JoinVertical(lipgloss.Left, titleView, descriptionView, meta)
 
 	return headerContainerStyle.Render(joinedView)
@@ -56,5 +69,8 @@ func (h *CommentsHeader) SetContent(comments model.Comments) {
 	h.Title = utils.NormalizeCommunity(comments.Community)
 	h.Description = comments.PostTitle
 	h.Author = comments.PostAuthor
+	h.Verified = comments.PostVerified
+	h.Score = comments.PostScore
 	h.Timestamp = comments.PostTimestamp
+	h.Edited = comments.PostEdited
 }
@@ -7,11 +7,17 @@ type viewportKeyMap struct {
 	CursorDown       key.Binding
 	GoToStart        key.Binding
 	GoToEnd          key.Binding
+	NextComment      key.Binding
+	PrevComment      key.Binding
 	OpenPost         key.Binding
 	GoHome           key.Binding
 	CollapseComments key.Binding
+	ToggleRaw        key.Binding
 	Reply            key.Binding
+	React            key.Binding
 	Copy             key.Binding
+	Edit             key.Binding
+	Delete           key.Binding
 	ShowFullHelp     key.Binding
 	CloseFullHelp    key.Binding
 	Quit             key.Binding
@@ -35,6 +41,14 @@ var commentsKeys = viewportKeyMap{
 		key.WithKeys("end", "G"),
 		key.WithHelp("G/end", "go to end"),
 	),
+	NextComment: key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "next comment"),
+	),
+	PrevComment: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "prev comment"),
+	),
 	OpenPost: key.NewBinding(
 		key.WithKeys("o", "O"),
 		key.WithHelp("o", "open post"),
@@ -47,12 +61,25 @@ var commentsKeys = viewportKeyMap{
 		key.WithKeys("c"),
 		key.WithHelp("c", "collapse comments"),
 	),
+	ToggleRaw: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "toggle markdown source"),
+	),
 	Reply: key.NewBinding(
 		key.WithKeys("r"),
-		key.WithHelp("r", "reply")),
+		key.WithHelp("r", "reply to selected")),
+	React: key.NewBinding(
+		key.WithKeys("+", "-"),
+		key.WithHelp("+/-", "upvote/downvote")),
 	Copy: key.NewBinding(
 		key.WithKeys("y"),
 		key.WithHelp("y", "copy nevent")),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit own post")),
+	Delete: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "delete own post/reply")),
 	ShowFullHelp: key.NewBinding(
 		key.WithKeys("?"),
 		key.WithHelp("?", "more"),
@@ -74,7 +101,8 @@ func (k viewportKeyMap) ShortHelp() []key.Binding {
 
 func (k viewportKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.CursorUp, k.CursorDown, k.GoToStart, k.GoToEnd, k.OpenPost},
-		{k.GoHome, k.Reply, k.Copy, k.CollapseComments, k.Quit, k.CloseFullHelp},
+		{k.CursorUp, k.CursorDown, k.GoToStart, k.GoToEnd, k.OpenPost, k.ToggleRaw},
+		{k.NextComment, k.PrevComment, k.Reply, k.React, k.Copy, k.Edit, k.Delete, k.CollapseComments},
+		{k.GoHome, k.Quit, k.CloseFullHelp},
 	}
 }
@@ -0,0 +1,603 @@
+package comments
+
+import (
+	"regexp"
+	"strings"
+	"tuistr/utils"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const escapableChars = "\\`*_~#>+-.!()[]{}|"
+
+var (
+	headingRegex  = regexp.MustCompile(`^ {0,3}(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	fenceRegex    = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([^`\\s]*)")
+	ruleRegex     = regexp.MustCompile(`^ {0,3}(?:(?:- *){3,}|(?:\* *){3,}|(?:_ *){3,})$`)
+	quoteRegex    = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	listItemRegex = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])\s+(.*)$`)
+)
+
+// renderMarkdown draws the Markdown most posts use (headings, emphasis, lists,
+// block quotes and fenced code) wrapped to width. Line breaks are kept as written,
+// since plenty of clients post plain text, and anything outside the subset such as
+// links, images, tables or HTML is shown as it is. No line is wider than width,
+// as long as width fits the widest rune.
+func renderMarkdown(source string, width int, base lipgloss.Style) string {
+	width = max(width, 1)
+	lines := strings.Split(cleanText(source), "\n")
+
+	var out []string
+	blank := true
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.TrimSpace(line) == "" {
+			// collapse runs of blank lines
+			if !blank {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+
+		if m := fenceRegex.FindStringSubmatch(line); m != nil {
+			end := i + 1
+			for end < len(lines) && !isClosingFence(lines[end], m[1]) {
+				end++
+			}
+			out = append(out, renderCode(lines[i+1:min(end, len(lines))], m[2], width)...)
+			i = end
+			continue
+		}
+
+		if m := headingRegex.FindStringSubmatch(line); m != nil {
+			style := markdownHeadingStyle
+			if len(m[1]) == 1 {
+				style = style.Underline(true)
+			}
+			out = append(out, wrapStyled(renderInline(m[2], style), width, true)...)
+			continue
+		}
+
+		if ruleRegex.MatchString(line) {
+			out = append(out, markdownBarStyle.Render(strings.Repeat("─", width)))
+			continue
+		}
+
+		if quoteRegex.MatchString(line) {
+			var quoted []string
+			for ; i < len(lines); i++ {
+				m := quoteRegex.FindStringSubmatch(lines[i])
+				if m == nil {
+					break
+				}
+				quoted = append(quoted, m[1])
+			}
+			i--
+
+			// quotes nested deeper than the width allows lose their bars
+			bar, innerWidth := markdownBarStyle.Render("│ "), width-2
+			if innerWidth < 1 {
+				bar, innerWidth = "", width
+			}
+			inner := renderMarkdown(strings.Join(quoted, "\n"), innerWidth, markdownQuoteStyle)
+			for _, quotedLine := range strings.Split(inner, "\n") {
+				out = append(out, bar+quotedLine)
+			}
+			continue
+		}
+
+		if m := listItemRegex.FindStringSubmatch(line); m != nil {
+			out = append(out, renderListItem(len(m[1]), m[2], m[3], width, base)...)
+			continue
+		}
+
+		out = append(out, wrapStyled(renderInline(strings.TrimSpace(line), base), width, true)...)
+	}
+
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
+
+// cleanText expands tabs and drops control characters other than newlines, so a
+// post can't move the cursor or send escape sequences to the terminal.
+func cleanText(text string) string {
+	return utils.StripControl(strings.ReplaceAll(text, "\t", "    "))
+}
+
+func isClosingFence(line, fence string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+// renderListItem draws one list item with a hanging indent. Nesting follows the
+// item's own indentation, two spaces a level, until the indent would take more
+// than half the width.
+func renderListItem(indent int, marker, text string, width int, base lipgloss.Style) []string {
+	if marker == "-" || marker == "*" || marker == "+" {
+		marker = "‣"
+	}
+	prefix := strings.Repeat("  ", indent/2) + marker + " "
+	if lipgloss.Width(prefix) > width/2 {
+		prefix = marker + " "
+	}
+	if lipgloss.Width(prefix) >= width {
+		prefix = ""
+	}
+	prefixWidth := lipgloss.Width(prefix)
+
+	lines := wrapStyled(renderInline(text, base), width-prefixWidth, true)
+	for i := range lines {
+		if i == 0 {
+			lines[i] = markdownBulletStyle.Render(prefix) + lines[i]
+		} else {
+			lines[i] = strings.Repeat(" ", prefixWidth) + lines[i]
+		}
+	}
+	return lines
+}
+
+// renderCode draws a fenced code block behind a bar, highlighted when the fence
+// names a language we know. Long lines are broken rather than reflowed.
+func renderCode(lines []string, lang string, width int) []string {
+	bar, codeWidth := markdownBarStyle.Render("┃ "), width-2
+	if codeWidth < 1 {
+		bar, codeWidth = "", width
+	}
+	if len(lines) == 0 {
+		return []string{bar}
+	}
+
+	var out []string
+	for _, line := range highlight(strings.Join(lines, "\n"), lang) {
+		for _, wrapped := range wrapStyled(line, codeWidth, false) {
+			out = append(out, bar+wrapped)
+		}
+	}
+	return out
+}
+
+// highlight splits code into lines of styled tokens.
+func highlight(code, lang string) [][]styledText {
+	tokens := []styledText{{code, markdownCodeStyle}}
+	if lexer := lexers.Get(lang); lang != "" && lexer != nil {
+		if iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code); err == nil {
+			tokens = tokens[:0]
+			for _, token := range iterator.Tokens() {
+				tokens = append(tokens, styledText{token.Value, tokenStyle(token.Type)})
+			}
+		}
+	}
+
+	// tokens may span lines, and each line is styled on its own
+	lines := [][]styledText{nil}
+	for _, token := range tokens {
+		for i, part := range strings.Split(token.text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], styledText{part, token.style})
+			}
+		}
+	}
+	if len(lines) > 1 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func tokenStyle(t chroma.TokenType) lipgloss.Style {
+	switch {
+	case t == chroma.NameFunction || t == chroma.NameClass:
+		return codeFunctionStyle
+	case t == chroma.NameBuiltin || t == chroma.NameBuiltinPseudo:
+		return codeBuiltinStyle
+	case t.InCategory(chroma.Keyword):
+		return codeKeywordStyle
+	case t.InSubCategory(chroma.LiteralString):
+		return codeStringStyle
+	case t.InSubCategory(chroma.LiteralNumber):
+		return codeNumberStyle
+	case t.InCategory(chroma.Comment):
+		return codeCommentStyle
+	case t.InCategory(chroma.Operator):
+		return codeOperatorStyle
+	default:
+		return codeTextStyle
+	}
+}
+
+// styledText is a run of text drawn in one style.
+type styledText struct {
+	text  string
+	style lipgloss.Style
+}
+
+// wrapStyled lays runs out in lines at most width cells wide and styles each line
+// on its own, so a wrapped line keeps its emphasis. With words set, lines break
+// between words and only words wider than a line are split; otherwise lines
+// break anywhere, which suits code.
+func wrapStyled(runs []styledText, width int, words bool) []string {
+	width = max(width, 1)
+
+	var (
+		lines   [][]styledText
+		line    []styledText
+		lineW   int
+		lastRun = -1
+		newLine = func() {
+			lines = append(lines, line)
+			line, lineW, lastRun = nil, 0, -1
+		}
+		appendTo = func(run int, text string) {
+			if lastRun == run {
+				line[len(line)-1].text += text
+			} else {
+				line = append(line, styledText{text, runs[run].style})
+			}
+			lineW += runewidth.StringWidth(text)
+			lastRun = run
+		}
+	)
+
+	for i, run := range runs {
+		for _, piece := range splitPieces(run.text, words) {
+			pieceW := runewidth.StringWidth(piece)
+			space := strings.TrimSpace(piece) == ""
+
+			if lineW > 0 && lineW+pieceW > width {
+				newLine()
+			}
+			if words && space && lineW == 0 && len(lines) > 0 {
+				// a wrapped line doesn't start with the space it wrapped at
+				continue
+			}
+
+			for pieceW > width-lineW {
+				head := runewidth.Truncate(piece, width-lineW, "")
+				if head == "" {
+					if lineW == 0 {
+						// a single rune wider than the line
+						head = string([]rune(piece)[:1])
+					} else {
+						newLine()
+						continue
+					}
+				}
+				appendTo(i, head)
+				newLine()
+				piece = piece[len(head):]
+				pieceW = runewidth.StringWidth(piece)
+			}
+			if piece != "" {
+				appendTo(i, piece)
+			}
+		}
+	}
+	lines = append(lines, line)
+
+	rendered := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if words && len(line) > 0 {
+			last := &line[len(line)-1]
+			last.text = strings.TrimRight(last.text, " ")
+		}
+
+		var sb strings.Builder
+		for _, run := range line {
+			if run.text != "" {
+				sb.WriteString(run.style.Render(run.text))
+			}
+		}
+		rendered = append(rendered, sb.String())
+	}
+	return rendered
+}
+
+// splitPieces cuts text into the units wrapStyled won't break: words and the
+// spaces between them, or single characters.
+func splitPieces(text string, words bool) []string {
+	if !words {
+		return strings.Split(text, "")
+	}
+
+	var pieces []string
+	start := 0
+	for i := 1; i <= len(text); i++ {
+		if i == len(text) || (text[i] == ' ') != (text[i-1] == ' ') {
+			pieces = append(pieces, text[start:i])
+			start = i
+		}
+	}
+	return pieces
+}
+
+// inlineSpan is a run of text with the same emphasis.
+type inlineSpan struct {
+	text   string
+	bold   bool
+	italic bool
+	strike bool
+	code   bool
+}
+
+func renderInline(text string, base lipgloss.Style) []styledText {
+	spans := parseInline(text)
+	runs := make([]styledText, 0, len(spans))
+	for _, span := range spans {
+		style := base
+		if span.code {
+			style = markdownCodeStyle
+		}
+		if span.bold {
+			style = style.Bold(true)
+		}
+		if span.italic {
+			style = style.Italic(true)
+		}
+		if span.strike {
+			style = style.Strikethrough(true)
+		}
+		runs = append(runs, styledText{span.text, style})
+	}
+	return runs
+}
+
+type emphasis int
+
+const (
+	emphasisBold emphasis = iota
+	emphasisItalic
+	emphasisStrike
+)
+
+// inlineNode is a piece of a line: plain text, a code span, or a run of
+// emphasis delimiters. A delimiter run keeps count delimiters it didn't match,
+// which are shown as they are, and the emphasis it opens and closes.
+type inlineNode struct {
+	text     string
+	code     bool
+	delim    byte
+	count    int
+	canOpen  bool
+	canClose bool
+	opens    []emphasis
+	closes   []emphasis
+}
+
+// parseInline splits text into code spans and runs of **bold**, *italic* and
+// ~~struck~~ text. Emphasis is matched with a stack of open delimiters, so it
+// takes one pass however the delimiters are nested or left unclosed.
+func parseInline(text string) []inlineSpan {
+	nodes := scanInline(text)
+	matchEmphasis(nodes)
+	return flattenInline(nodes)
+}
+
+// scanInline cuts text into nodes, resolving escapes and code spans as it goes.
+func scanInline(text string) []inlineNode {
+	var (
+		nodes []inlineNode
+		plain strings.Builder
+	)
+	flush := func() {
+		if plain.Len() > 0 {
+			nodes = append(nodes, inlineNode{text: plain.String()})
+			plain.Reset()
+		}
+	}
+
+	ticks := newBacktickIndex(text)
+	for i := 0; i < len(text); {
+		switch c := text[i]; {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte(escapableChars, text[i+1]) >= 0:
+			plain.WriteByte(text[i+1])
+			i += 2
+
+		case c == '`':
+			n := runLength(text, i)
+			if end := ticks.closing(i, n); end >= 0 {
+				flush()
+				nodes = append(nodes, inlineNode{text: strings.TrimSpace(text[i+n : end]), code: true})
+				i = end + n
+				continue
+			}
+			plain.WriteString(text[i : i+n])
+			i += n
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(text, i)
+			flush()
+			nodes = append(nodes, delimiterRun(text, i, n))
+			i += n
+
+		default:
+			plain.WriteByte(c)
+			i++
+		}
+	}
+
+	flush()
+	return nodes
+}
+
+// delimiterRun describes the n delimiters at text[i]. A run opens when text
+// follows it and closes when text precedes it; underscores don't count inside
+// words so snake_case survives, and a lone tilde is just a tilde.
+func delimiterRun(text string, i, n int) inlineNode {
+	before, after := byte(' '), byte(' ')
+	if i > 0 {
+		before = text[i-1]
+	}
+	if i+n < len(text) {
+		after = text[i+n]
+	}
+
+	run := inlineNode{delim: text[i], count: n, canOpen: after != ' ', canClose: before != ' '}
+	switch run.delim {
+	case '_':
+		run.canOpen = run.canOpen && !isWordByte(before)
+		run.canClose = run.canClose && !isWordByte(after)
+	case '~':
+		run.canOpen = run.canOpen && n >= 2
+		run.canClose = run.canClose && n >= 2
+	}
+	return run
+}
+
+// matchEmphasis pairs each closing run with the nearest open run of the same
+// delimiter. Runs of other delimiters opened in between can't close outside the
+// new span, so they are dropped; every run is pushed and dropped at most once.
+func matchEmphasis(nodes []inlineNode) {
+	open := make(map[byte][]int)
+
+	for i := range nodes {
+		closer := &nodes[i]
+		if closer.delim == 0 {
+			continue
+		}
+
+		for closer.canClose && closer.count > 0 && len(open[closer.delim]) > 0 {
+			stack := open[closer.delim]
+			at := stack[len(stack)-1]
+			opener := &nodes[at]
+
+			used := min(opener.count, closer.count, 2)
+			if closer.delim == '~' {
+				used = 2
+			}
+			e := emphasisItalic
+			switch {
+			case closer.delim == '~':
+				e = emphasisStrike
+			case used == 2:
+				e = emphasisBold
+			}
+
+			opener.count -= used
+			opener.opens = append(opener.opens, e)
+			closer.count -= used
+			closer.closes = append(closer.closes, e)
+
+			if opener.count == 0 || (opener.delim == '~' && opener.count < 2) {
+				open[closer.delim] = stack[:len(stack)-1]
+			}
+			for delim, others := range open {
+				for delim != closer.delim && len(others) > 0 && others[len(others)-1] > at {
+					others = others[:len(others)-1]
+				}
+				open[delim] = others
+			}
+		}
+
+		if closer.canOpen && closer.count > 0 && (closer.delim != '~' || closer.count >= 2) {
+			open[closer.delim] = append(open[closer.delim], i)
+		}
+	}
+}
+
+// flattenInline turns matched nodes into spans. A run's closing delimiters sit on
+// its left and its opening ones on its right, with any unmatched ones between.
+func flattenInline(nodes []inlineNode) []inlineSpan {
+	var (
+		spans   []inlineSpan
+		active  [3]int
+		pending inlineSpan
+		plain   strings.Builder
+	)
+	flush := func() {
+		if plain.Len() > 0 {
+			span := pending
+			span.text = plain.String()
+			spans = append(spans, span)
+			plain.Reset()
+		}
+	}
+	emit := func(text string) {
+		if text == "" {
+			return
+		}
+		span := inlineSpan{bold: active[emphasisBold] > 0, italic: active[emphasisItalic] > 0, strike: active[emphasisStrike] > 0}
+		if span != pending {
+			flush()
+			pending = span
+		}
+		plain.WriteString(text)
+	}
+
+	for _, node := range nodes {
+		switch {
+		case node.code:
+			flush()
+			spans = append(spans, inlineSpan{text: node.text, code: true})
+		case node.delim != 0:
+			for _, e := range node.closes {
+				active[e]--
+			}
+			emit(strings.Repeat(string(node.delim), node.count))
+			for _, e := range node.opens {
+				active[e]++
+			}
+		default:
+			emit(node.text)
+		}
+	}
+
+	flush()
+	return spans
+}
+
+// backtickIndex finds where code spans close. A span opened by n backticks
+// closes at the next run of exactly n, and since spans are looked up left to
+// right each run length only ever moves forward through its runs.
+type backtickIndex struct {
+	runs map[int][]int
+	next map[int]int
+}
+
+func newBacktickIndex(text string) backtickIndex {
+	index := backtickIndex{runs: make(map[int][]int), next: make(map[int]int)}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '`' {
+			n := runLength(text, i)
+			index.runs[n] = append(index.runs[n], i)
+			i += n - 1
+		}
+	}
+	return index
+}
+
+// closing returns where the span of n backticks opened at i closes, or -1.
+func (b backtickIndex) closing(i, n int) int {
+	runs := b.runs[n]
+	next := b.next[n]
+	for next < len(runs) && runs[next] < i+n {
+		next++
+	}
+	b.next[n] = next
+	if next < len(runs) {
+		return runs[next]
+	}
+	return -1
+}
+
+// runLength counts how often text[i] repeats from i on.
+func runLength(text string, i int) int {
+	n := 1
+	for i+n < len(text) && text[i+n] == text[i] {
+		n++
+	}
+	return n
+}
+
+func isWordByte(b byte) bool {
+	return b >= 0x80 || b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
@@ -0,0 +1,122 @@
+package comments
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var sgrRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// plainLines renders source and returns its lines without styling.
+func plainLines(source string, width int) []string {
+	rendered := renderMarkdown(source, width, lipgloss.NewStyle())
+	return strings.Split(sgrRegex.ReplaceAllString(rendered, ""), "\n")
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		text string
+		want []inlineSpan
+	}{
+		{"plain", []inlineSpan{{text: "plain"}}},
+		{"**bold**", []inlineSpan{{text: "bold", bold: true}}},
+		{"**bold *both* bold**", []inlineSpan{
+			{text: "bold ", bold: true},
+			{text: "both", bold: true, italic: true},
+			{text: " bold", bold: true},
+		}},
+		{"***both***", []inlineSpan{{text: "both", bold: true, italic: true}}},
+		{"_a_ and __b__", []inlineSpan{
+			{text: "a", italic: true},
+			{text: " and "},
+			{text: "b", bold: true},
+		}},
+		{"snake_case_name", []inlineSpan{{text: "snake_case_name"}}},
+		{"a_b and _c_", []inlineSpan{{text: "a_b and "}, {text: "c", italic: true}}},
+		{"~~gone~~ ~kept~", []inlineSpan{{text: "gone", strike: true}, {text: " ~kept~"}}},
+		{"*open and **unclosed", []inlineSpan{{text: "*open and **unclosed"}}},
+		{"2 * 3 * 4", []inlineSpan{{text: "2 * 3 * 4"}}},
+		{"*a _b* c_", []inlineSpan{{text: "a _b", italic: true}, {text: " c_"}}},
+		{"`a*b*` *c*", []inlineSpan{{text: "a*b*", code: true}, {text: " "}, {text: "c", italic: true}}},
+		{"``a`b`` `x", []inlineSpan{{text: "a`b", code: true}, {text: " `x"}}},
+		{`\*not\* *yes*`, []inlineSpan{{text: "*not* "}, {text: "yes", italic: true}}},
+	}
+
+	for _, tt := range tests {
+		if got := parseInline(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseInline(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseInlineUnclosedDelimiters(t *testing.T) {
+	text := strings.Repeat("*a _b ~~c ", 20000)
+	spans := parseInline(text)
+	if len(spans) != 1 || spans[0].text != text {
+		t.Fatalf("expected unclosed delimiters to stay as written, got %d spans", len(spans))
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		width  int
+		want   []string
+	}{
+		{"heading", "# Title\ntext", 20, []string{"Title", "text"}},
+		{"closed heading", "## Sub ##", 20, []string{"Sub"}},
+		{"wrapped", "one two three", 7, []string{"one two", "three"}},
+		{"blank runs", "a\n\n\n\nb\n\n", 20, []string{"a", "", "b"}},
+		{"unclosed fence", "```go\nx := 1\n*not emphasis*", 20, []string{"┃ x := 1", "┃ *not emphasis*"}},
+		{"empty fence", "```", 20, []string{"┃ "}},
+		{"nested quotes", "> a\n> > b\n> c", 20, []string{"│ a", "│ │ b", "│ c"}},
+		{"quoted list", "> - a\n> - b", 20, []string{"│ ‣ a", "│ ‣ b"}},
+		{"nested lists", "- a\n  - b\n    1. c\n- d", 20, []string{"‣ a", "  ‣ b", "    1. c", "‣ d"}},
+		{"hanging indent", "- one two three", 9, []string{"‣ one two", "  three"}},
+		{"wide runes", "世界世界世界", 5, []string{"世界", "世界", "世界"}},
+		{"tabs", "\tx", 20, []string{"x"}},
+		{"control characters", "a\x1b]0;title\x07b\x1b[2Jc\r\nd\u009be", 20, []string{"a]0;titleb[2Jc", "de"}},
+	}
+
+	for _, tt := range tests {
+		if got := plainLines(tt.source, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRenderMarkdownFitsWidth(t *testing.T) {
+	sources := []string{
+		"# A heading long enough to wrap around",
+		"plain words, and a_very_long_identifier_that_has_to_break somewhere",
+		"**bold *italic* text** with ~~struck~~ words and `code spans`",
+		"> > > > > > deeply quoted text that keeps going",
+		"- a\n  - b\n    - c\n      - d\n        - e\n          123456789. f",
+		"```go\nfunc main() { fmt.Println(\"a long line of code\") }\n```",
+		"世界世界世界世界 wide runes 世界",
+		"---",
+	}
+
+	for _, source := range sources {
+		for _, width := range []int{2, 5, 12, 30, 80} {
+			for _, line := range strings.Split(renderMarkdown(source, width, lipgloss.NewStyle()), "\n") {
+				if lipgloss.Width(line) > width {
+					t.Errorf("line %q of %q is wider than %d", line, source, width)
+				}
+			}
+		}
+	}
+}
+
+func TestRawTextDropsControlCharacters(t *testing.T) {
+	c := NewCommentsViewport()
+	c.raw = true
+	if got := c.renderText("a\x1b[31mb\x07", 20, lipgloss.NewStyle()); strings.ContainsAny(got, "\x1b\x07") {
+		t.Fatalf("expected control characters to be dropped, got %q", got)
+	}
+}
@@ -2,8 +2,10 @@ package comments
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"tuistr/model"
+	"tuistr/utils"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -17,20 +19,37 @@ type CommentsViewport struct {
 	postText      string
 	postTitle     string
 	postUrl       string
+	postDeleted   bool
 	comments      []model.Comment
 	keyMap        viewportKeyMap
 	help          help.Model
 	collapsed     bool
+	raw           bool
 	viewportLines []string
+	cursor        int
+	commentLines  map[int]int
+	bodies        map[bodyKey]string
 	w, h          int
 }
 
+// bodyKey identifies a drawn post or comment body. Bodies only change with their
+// text, width and the raw toggle, so moving the cursor doesn't draw them again.
+type bodyKey struct {
+	text  string
+	width int
+	depth int
+	post  bool
+	raw   bool
+}
+
 func NewCommentsViewport() CommentsViewport {
 	return CommentsViewport{
 		viewport:  viewport.New(0, 0),
 		keyMap:    commentsKeys,
 		help:      help.New(),
 		collapsed: false,
+		cursor:    -1,
+		bodies:    make(map[bodyKey]string),
 	}
 }
 
@@ -42,8 +61,17 @@ func (c CommentsViewport) Update(msg tea.Msg) (CommentsViewport, tea.Cmd) {
 			c.viewport.GotoTop()
 		case key.Matches(msg, c.keyMap.GoToEnd):
 			c.viewport.GotoBottom()
+		case key.Matches(msg, c.keyMap.NextComment):
+			c.moveCursor(1)
+			return c, nil
+		case key.Matches(msg, c.keyMap.PrevComment):
+			c.moveCursor(-1)
+			return c, nil
 		case key.Matches(msg, c.keyMap.CollapseComments):
 			c.toggleCollapseComments()
+		case key.Matches(msg, c.keyMap.ToggleRaw):
+			c.toggleRaw()
+			return c, nil
 		case key.Matches(msg, c.keyMap.ShowFullHelp),
 			key.Matches(msg, c.keyMap.CloseFullHelp):
 			c.help.ShowAll = !c.help.ShowAll
@@ -62,7 +90,10 @@ func (c CommentsViewport) View() string {
 }
 
 func (c *CommentsViewport) SetSize(w, h int) {
-	c.w = w - viewportStyle.GetHorizontalFrameSize()
+	if width := w - viewportStyle.GetHorizontalFrameSize(); width != c.w {
+		c.w = width
+		clear(c.bodies)
+	}
 	c.h = h
 
 	c.ResizeComponents()
@@ -73,14 +104,130 @@ func (c *CommentsViewport) SetContent(comments model.Comments) {
 	c.postText = comments.PostText
 	c.postTitle = comments.PostTitle
 	c.postUrl = comments.PostUrl
+	c.postDeleted = comments.PostDeleted
 	c.comments = comments.Comments
+	clear(c.bodies)
 
 	c.collapsed = false
+	c.cursor = -1
 	c.viewport.SetYOffset(0)
 	c.ResizeComponents()
 	c.SetViewportContent()
 }
 
+// RefreshContent replaces the comments without moving the viewport, keeping the
+// selected comment when it is still part of the thread.
+func (c *CommentsViewport) RefreshContent(comments model.Comments) {
+	selected, hasSelection := c.SelectedComment()
+	offset := c.viewport.YOffset
+
+	c.postText = comments.PostText
+	c.postTitle = comments.PostTitle
+	c.postDeleted = comments.PostDeleted
+	c.comments = comments.Comments
+
+	c.cursor = -1
+	if hasSelection {
+		for i, comment := range c.comments {
+			if comment.ID == selected.ID {
+				c.cursor = i
+				break
+			}
+		}
+	}
+
+	c.SetViewportContent()
+	c.viewport.SetYOffset(offset)
+}
+
+// AppendComment inserts a live reply below its parent (or at the end for replies
+// to the post) without moving the comments currently on screen.
+func (c *CommentsViewport) AppendComment(comment model.Comment) {
+	for _, existing := range c.comments {
+		if existing.ID == comment.ID {
+			return
+		}
+	}
+
+	pos := len(c.comments)
+	comment.Depth = 0
+	for i, existing := range c.comments {
+		if existing.ID != comment.ParentID {
+			continue
+		}
+
+		comment.Depth = existing.Depth + 1
+		pos = i + 1
+		for pos < len(c.comments) && c.comments[pos].Depth > existing.Depth {
+			pos++
+		}
+		break
+	}
+
+	// Remember which comment sits at the top of the screen
+	anchor, anchorDelta := -1, 0
+	for i := range c.comments {
+		if line, ok := c.commentLines[i]; ok && line >= c.viewport.YOffset {
+			anchor, anchorDelta = i, c.viewport.YOffset-line
+			break
+		}
+	}
+
+	// Build a new slice; the old one may be shared with the client's thread cache
+	comments := make([]model.Comment, 0, len(c.comments)+1)
+	comments = append(comments, c.comments[:pos]...)
+	comments = append(comments, comment)
+	c.comments = append(comments, c.comments[pos:]...)
+	if c.cursor >= pos {
+		c.cursor++
+	}
+	if anchor >= pos {
+		anchor++
+	}
+
+	offset := c.viewport.YOffset
+	c.SetViewportContent()
+	if line, ok := c.commentLines[anchor]; ok {
+		offset = line + anchorDelta
+	}
+	c.viewport.SetYOffset(offset)
+}
+
+// SetScores updates comment reaction scores in place, keeping the scroll position.
+func (c *CommentsViewport) SetScores(scores map[string]int) {
+	changed := false
+	for i, comment := range c.comments {
+		if score, ok := scores[comment.ID]; ok && comment.Score != score {
+			c.comments[i].Score = score
+			changed = true
+		}
+	}
+
+	if changed {
+		offset := c.viewport.YOffset
+		c.SetViewportContent()
+		c.viewport.SetYOffset(offset)
+	}
+}
+
+// SetAuthors updates comment author names in place, keeping the scroll position.
+func (c *CommentsViewport) SetAuthors(profiles map[string]model.Profile) {
+	changed := false
+	for i, comment := range c.comments {
+		if profile, ok := profiles[comment.PubKey]; ok && (comment.Author != profile.Name || comment.Verified != profile.Verified) {
+			c.comments[i].Author = profile.Name
+			c.comments[i].Verified = profile.Verified
+			changed = true
+		}
+	}
+
+	if changed {
+		offset := c.viewport.YOffset
+		c.SetViewportContent()
+		c.viewport.SetYOffset(offset)
+	}
+}
+
 func (c *CommentsViewport) ResizeComponents() {
 	helpHeight := lipgloss.Height(c.help.View(c.keyMap))
 
@@ -89,26 +236,56 @@ func (c *CommentsViewport) ResizeComponents() {
 }
 
 func (c *CommentsViewport) GetViewportView() string {
-	var content strings.Builder
+	var (
+		content strings.Builder
+		lines   int
+	)
+	c.commentLines = make(map[int]int, len(c.comments))
 
 	// Show the post body once; if it mirrors the title, skip it to avoid duplication.
-	if strings.TrimSpace(c.postText) != "" && !sameText(c.postText, c.postTitle) {
-		content.WriteString(c.postText)
+	if c.postDeleted {
+		content.WriteString(deletedStyle.Render(deletedText))
+		content.WriteString("\n\n")
+		lines += 2
+	} else if strings.TrimSpace(c.postText) != "" && !sameText(c.postText, c.postTitle) {
+		body := c.cachedBody(bodyKey{text: c.postText, width: c.w, post: true, raw: c.raw}, func() string {
+			return c.renderText(c.postText, c.w, lipgloss.NewStyle())
+		})
+		content.WriteString(body)
 		content.WriteString("\n\n")
+		lines += strings.Count(body, "\n") + 2
 	}
 
 	for i := range len(c.comments) {
 		comment := c.comments[i]
 		commentView := c.formatComment(comment, i)
 		if len(commentView) > 0 {
+			c.commentLines[i] = lines
 			content.WriteString(commentView)
 			content.WriteString("\n\n")
+			lines += strings.Count(commentView, "\n") + 2
 		}
 	}
 
 	return content.String()
 }
 
+// RenderThread draws thread the way the thread view does, at width, so a
+// post can be previewed before it exists.
+func RenderThread(thread model.Comments, width int) string {
+	header := NewCommentsHeader()
+	header.SetSize(width, 0)
+	header.SetContent(thread)
+
+	pager := NewCommentsViewport()
+	pager.w = width
+	pager.postText = thread.PostText
+	pager.postTitle = thread.PostTitle
+	pager.comments = thread.Comments
+
+	return lipgloss.JoinVertical(lipgloss.Left, header.View(), strings.TrimRight(pager.GetViewportView(), "\n"))
+}
+
 func sameText(a, b string) bool {
 	return strings.TrimSpace(a) == strings.TrimSpace(b)
 }
@@ -133,8 +310,15 @@ func (c *CommentsViewport) formatComment(comment model.Comment, i int) string {
 	}
 
 	authorView := commentAuthorStyle.Render(comment.Author)
+	if comment.Verified {
+		authorView = fmt.Sprintf("%s %s", authorView, verifiedStyle.Render("✓"))
+	}
 	dateView := commentDateStyle.Render(comment.Timestamp)
-	metaView = fmt.Sprintf("%s • %s", authorView, dateView)
+	scoreView := commentScoreStyle.Render(utils.GetSingularPlural(strconv.Itoa(comment.Score), "point", "points"))
+	metaView = fmt.Sprintf("%s • %s  %s", authorView, dateView, scoreView)
+	if i == c.cursor {
+		metaView = fmt.Sprintf("%s %s", selectedMarkerStyle.Render("▸"), metaView)
+	}
 
 	if c.collapsed {
 		children := 0
@@ -158,9 +342,97 @@ func (c *CommentsViewport) formatComment(comment model.Comment, i int) string {
 		metaLine = fmt.Sprintf("%s  %s", metaView, collapsedHint)
 	}
 
-	body := commentTextStyle.Render(comment.Text)
-	joined := lipgloss.JoinVertical(lipgloss.Left, metaLine, body)
-	return containerStyle.Render(joined)
+	// only the meta line changes with the cursor, so the body is drawn once
+	body := containerStyle.Render(deletedStyle.Render(deletedText))
+	if !comment.Deleted {
+		body = c.cachedBody(bodyKey{text: comment.Text, width: c.w, depth: comment.Depth, raw: c.raw}, func() string {
+			return containerStyle.Render(c.renderText(comment.Text, c.w-2*paddingW, commentTextStyle))
+		})
+	}
+	return containerStyle.Render(metaLine) + "\n" + body
+}
+
+// cachedBody returns the body drawn for key, drawing it with render the first time.
+func (c *CommentsViewport) cachedBody(key bodyKey, render func() string) string {
+	if body, ok := c.bodies[key]; ok {
+		return body
+	}
+	body := render()
+	c.bodies[key] = body
+	return body
+}
+
+// renderText draws a post or comment body as Markdown, or as written while the
+// raw source is toggled on. Either way control characters are dropped first.
+func (c *CommentsViewport) renderText(text string, width int, style lipgloss.Style) string {
+	if c.raw {
+		return style.Render(cleanText(text))
+	}
+	return renderMarkdown(text, width, style)
+}
+
+// MarkDeleted blanks a comment its author deleted, leaving its replies in place.
+func (c *CommentsViewport) MarkDeleted(id string) {
+	for i := range c.comments {
+		if c.comments[i].ID == id {
+			c.comments[i].Deleted = true
+			c.comments[i].Text = ""
+
+			offset := c.viewport.YOffset
+			c.SetViewportContent()
+			c.viewport.SetYOffset(offset)
+			return
+		}
+	}
+}
+
+// SelectedComment returns the comment under the cursor, if any. With no selection
+// replies go to the thread root.
+func (c *CommentsViewport) SelectedComment() (model.Comment, bool) {
+	if c.cursor < 0 || c.cursor >= len(c.comments) {
+		return model.Comment{}, false
+	}
+	return c.comments[c.cursor], true
+}
+
+// Move the comment cursor by delta visible comments. Moving above the first comment
+// clears the selection so replies target the post again.
+func (c *CommentsViewport) moveCursor(delta int) {
+	next := c.cursor
+	for {
+		next += delta
+		if next < 0 {
+			c.cursor = -1
+			break
+		}
+		if next >= len(c.comments) {
+			return
+		}
+		if !c.collapsed || c.comments[next].Depth == 0 {
+			c.cursor = next
+			break
+		}
+	}
+
+	c.SetViewportContent()
+	c.scrollToCursor()
+}
+
+// Keep the selected comment's meta line on screen.
+func (c *CommentsViewport) scrollToCursor() {
+	if c.cursor < 0 {
+		c.viewport.GotoTop()
+		return
+	}
+
+	line, ok := c.commentLines[c.cursor]
+	if !ok {
+		return
+	}
+
+	if line < c.viewport.YOffset || line >= c.viewport.YOffset+c.viewport.Height-2 {
+		c.viewport.SetYOffset(line)
+	}
 }
 
 func (c *CommentsViewport) toggleCollapseComments() {
@@ -172,12 +444,37 @@ func (c *CommentsViewport) toggleCollapseComments() {
 	offset := pos - c.viewport.YOffset
 
 	c.collapsed = !c.collapsed
+	if c.collapsed {
+		// Move a hidden selection up to its top level comment
+		for c.cursor > 0 && c.comments[c.cursor].Depth > 0 {
+			c.cursor--
+		}
+	}
 	c.SetViewportContent()
 
 	newPos := c.findComment(title, text)
 	c.viewport.SetYOffset(newPos - offset)
 }
 
+// toggleRaw switches between rendered Markdown and the raw source, keeping the
+// comment at the top of the screen where it was.
+func (c *CommentsViewport) toggleRaw() {
+	anchor, anchorLine := -1, -1
+	for i, line := range c.commentLines {
+		if line <= c.viewport.YOffset && line > anchorLine {
+			anchor, anchorLine = i, line
+		}
+	}
+	offset := c.viewport.YOffset - anchorLine
+
+	c.raw = !c.raw
+	c.SetViewportContent()
+
+	if line, ok := c.commentLines[anchor]; ok {
+		c.viewport.SetYOffset(line + offset)
+	}
+}
+
 // Find comment closest to the center of the screen to act as an anchor when toggling
 // child comments.
 func (c *CommentsViewport) findAnchorComment() (pos int, title string, text string) {
@@ -9,14 +9,40 @@ import (
 var viewportStyle = lipgloss.NewStyle().Margin(0, 2, 1, 2)
 
 var (
-	commentAuthorStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue)).Bold(true)
-	commentDateStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Lavender)).Italic(true)
-	commentTextStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text))
-	collapsedStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Yellow))
+	commentAuthorStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue)).Bold(true)
+	commentDateStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Lavender)).Italic(true)
+	commentTextStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text))
+	collapsedStyle      = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Yellow))
+	selectedMarkerStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Pink)).Bold(true)
+	verifiedStyle       = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Green))
+	commentScoreStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).Faint(true)
+	deletedStyle        = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).Italic(true)
 )
 
+const deletedText = "[deleted by author]"
+
 var (
 	postAuthorStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue))
 	postTextStyle      = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Sand))
 	postTimestampStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text)).Faint(true)
 )
+
+var (
+	markdownHeadingStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue)).Bold(true)
+	markdownBulletStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Lavender))
+	markdownQuoteStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).Italic(true)
+	markdownBarStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).Faint(true)
+	markdownCodeStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Maroon))
+)
+
+// syntax colors for fenced code
+var (
+	codeKeywordStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Purple))
+	codeFunctionStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Blue))
+	codeBuiltinStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Red))
+	codeStringStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Green))
+	codeNumberStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Orange))
+	codeCommentStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).Italic(true)
+	codeOperatorStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Pink))
+	codeTextStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text))
+)
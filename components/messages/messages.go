@@ -1,6 +1,8 @@
 package messages
 
 import (
+	"time"
+	"tuistr/config"
 	"tuistr/model"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,6 +13,15 @@ type ErrorModalMsg struct {
 	OnClose  tea.Cmd
 }
 
+type RelayAction int
+
+const (
+	ReconnectRelay RelayAction = iota
+	ToggleRelay
+	AddRelay
+	AllowRelayAuth
+)
+
 type (
 	GoBackMsg          struct{}
 	LoadThreadMsg      model.Post
@@ -18,33 +29,156 @@ type (
 	LoadMorePostsMsg   bool
 	LoadCommunityMsg   string
 	UpdateCommentsMsg  model.Comments
+	RefreshCommentsMsg model.Comments
 	UpdatePostsMsg     model.Posts
+	RefreshPostsMsg    model.Posts
 	AddMorePostsMsg    model.Posts
+	UpdateProfilesMsg  map[string]model.Profile
 	LoadingCompleteMsg struct{}
 	ShowComposePostMsg struct {
 		Community string
 	}
-	ShowReplyModalMsg model.Post
-	SubmitPostMsg     struct {
+	ShowReplyModalMsg struct {
+		Post   model.Post
+		Parent model.Comment
+	}
+	ShowEditModalMsg struct {
+		Post model.Post
+	}
+	SubmitPostMsg struct {
 		Community string
+		Title     string
 		Content   string
+		DraftKey  string
+		Confirmed bool
 	}
 	SubmitReplyMsg struct {
-		Post    model.Post
-		Content string
+		Post      model.Post
+		Parent    model.Comment
+		Content   string
+		DraftKey  string
+		Confirmed bool
+	}
+	SubmitEditMsg struct {
+		Post      model.Post
+		Title     string
+		Content   string
+		DraftKey  string
+		Confirmed bool
+	}
+	// PublishPreviewMsg shows what Confirm would publish
+	PublishPreviewMsg struct {
+		Preview model.PublishPreview
+		Confirm tea.Msg
+		Error   string
 	}
 	PostPublishedMsg  model.Post
 	ReplyPublishedMsg struct {
 		Post    model.Post
 		Comment model.Comment
 	}
+	// PostEditedMsg replaces Original with its published Revision
+	PostEditedMsg struct {
+		Original model.Post
+		Revision model.Post
+	}
 	PublishErrorMsg struct {
 		ErrorMsg string
 	}
-	CopyNeventMsg struct {
+	UpdateScoresMsg  map[string]int
+	UpdateRepliesMsg map[string]int
+	ReactMsg         struct {
+		EventID string
+		PubKey  string
+		Kind    int
+		Content string
+		// earlier versions of an edited post, whose reactions count too
+		Versions []string
+	}
+	// DeleteEventMsg asks to delete one of the user's own events; it is only
+	// published once Confirmed
+	DeleteEventMsg struct {
+		EventID   string
+		PubKey    string
+		Kind      int
+		Confirmed bool
+	}
+	EventDeletedMsg string
+	CopyNeventMsg   struct {
 		Post model.Post
 	}
 
+	LivePostMsg struct {
+		Source <-chan model.Post
+		Post   model.Post
+	}
+	LiveCommentMsg struct {
+		Source  <-chan model.Comment
+		Comment model.Comment
+	}
+
+	LoadRelaysMsg   struct{}
+	UpdateRelaysMsg struct {
+		Relays []model.RelayStatus
+		Error  string
+	}
+	RelayActionMsg struct {
+		Action RelayAction
+		URL    string
+	}
+	RelayTickMsg int
+
+	LoadRelayConfigMsg struct{}
+	RelayConfigMsg     struct {
+		Relays []config.RelayMode
+		Error  string
+	}
+	SaveRelayConfigMsg  []config.RelayMode
+	RelayConfigSavedMsg struct {
+		Error string
+	}
+	TestRelayMsg   string
+	RelayTestedMsg struct {
+		URL     string
+		Latency time.Duration
+		Error   string
+	}
+	FetchRelayInfoMsg string
+	RelayInfoMsg      struct {
+		URL   string
+		Info  model.RelayInfo
+		Error string
+	}
+
+	LoadAccountsMsg struct{}
+	AccountsMsg     struct {
+		Names   []string
+		Current string
+	}
+	SwitchAccountMsg string
+
+	// Retry is the publish or reaction that found the secret key locked
+	UnlockSignerMsg struct {
+		Passphrase string
+		Retry      tea.Msg
+	}
+	SignerUnlockedMsg struct {
+		Retry tea.Msg
+		Error string
+	}
+
+	SaveDraftMsg   model.Draft
+	DeleteDraftMsg string
+	LoadDraftsMsg  struct{}
+	DraftsMsg      []model.Draft
+	OpenDraftMsg   model.Draft
+
+	// EditorClosedMsg carries the text back from $EDITOR
+	EditorClosedMsg struct {
+		Content string
+		Error   string
+	}
+
 	OpenModalMsg        struct{}
 	ExitModalMsg        struct{}
 	ShowSpinnerModalMsg string
@@ -86,9 +220,33 @@ func ShowComposePost(community string) tea.Cmd {
 	}
 }
 
-func ShowReplyModal(post model.Post) tea.Cmd {
+func ShowReplyModal(post model.Post, parent model.Comment) tea.Cmd {
 	return func() tea.Msg {
-		return ShowReplyModalMsg(post)
+		return ShowReplyModalMsg{Post: post, Parent: parent}
+	}
+}
+
+func ShowEditModal(post model.Post) tea.Cmd {
+	return func() tea.Msg {
+		return ShowEditModalMsg{Post: post}
+	}
+}
+
+func React(eventID, pubKey string, kind int, content string) tea.Cmd {
+	return func() tea.Msg {
+		return ReactMsg{EventID: eventID, PubKey: pubKey, Kind: kind, Content: content}
+	}
+}
+
+func ReactToPost(post model.Post, content string) tea.Cmd {
+	return func() tea.Msg {
+		return ReactMsg{EventID: post.ID, PubKey: post.PubKey, Kind: post.Kind, Content: content, Versions: post.Revises}
+	}
+}
+
+func DeleteEvent(eventID, pubKey string, kind int) tea.Cmd {
+	return func() tea.Msg {
+		return DeleteEventMsg{EventID: eventID, PubKey: pubKey, Kind: kind}
 	}
 }
 
@@ -96,6 +254,22 @@ func LoadingComplete() tea.Msg {
 	return LoadingCompleteMsg{}
 }
 
+func LoadRelays() tea.Msg {
+	return LoadRelaysMsg{}
+}
+
+func LoadRelayConfig() tea.Msg {
+	return LoadRelayConfigMsg{}
+}
+
+func LoadAccounts() tea.Msg {
+	return LoadAccountsMsg{}
+}
+
+func LoadDrafts() tea.Msg {
+	return LoadDraftsMsg{}
+}
+
 func OpenModal() tea.Msg {
 	return OpenModalMsg{}
 }
@@ -131,3 +305,33 @@ func OpenUrl(url string) tea.Cmd {
 		return OpenUrlMsg(url)
 	}
 }
+
+// WaitForLivePost delivers the next post from a live subscription. The source is
+// passed along so pages can drop messages from subscriptions they replaced.
+func WaitForLivePost(source <-chan model.Post) tea.Cmd {
+	if source == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		post, ok := <-source
+		if !ok {
+			return nil
+		}
+		return LivePostMsg{Source: source, Post: post}
+	}
+}
+
+func WaitForLiveComment(source <-chan model.Comment) tea.Cmd {
+	if source == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		comment, ok := <-source
+		if !ok {
+			return nil
+		}
+		return LiveCommentMsg{Source: source, Comment: comment}
+	}
+}
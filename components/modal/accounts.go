@@ -0,0 +1,94 @@
+package modal
+
+import (
+	"slices"
+	"tuistr/components/messages"
+	"tuistr/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	accountsTitle = "Accounts"
+	accountsHelp  = "j/k move • enter switch • esc close"
+	accountsEmpty = "Only the default account is configured. Add more under [accounts.<name>] in the config file."
+)
+
+// AccountsModal lists the configured accounts and switches between them.
+type AccountsModal struct {
+	names   []string
+	current string
+	cursor  int
+	w       int
+}
+
+func NewAccountsModal() AccountsModal {
+	return AccountsModal{}
+}
+
+func (a AccountsModal) Init() tea.Cmd {
+	return nil
+}
+
+func (a *AccountsModal) Open() tea.Cmd {
+	a.names = nil
+	a.cursor = 0
+	return messages.LoadAccounts
+}
+
+func (a AccountsModal) Update(msg tea.Msg) (AccountsModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case messages.AccountsMsg:
+		a.names, a.current = msg.Names, msg.Current
+		a.cursor = max(slices.Index(a.names, a.current), 0)
+		return a, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			a.cursor = utils.Clamp(0, max(len(a.names)-1, 0), a.cursor+1)
+		case "k", "up":
+			a.cursor = utils.Clamp(0, max(len(a.names)-1, 0), a.cursor-1)
+		case "enter":
+			if len(a.names) == 0 {
+				return a, nil
+			}
+			if name := a.names[a.cursor]; name != a.current {
+				return a, func() tea.Msg { return messages.SwitchAccountMsg(name) }
+			}
+			return a, messages.ExitModal
+		case "esc", "q":
+			return a, messages.ExitModal
+		}
+	}
+
+	return a, nil
+}
+
+func (a AccountsModal) View() string {
+	views := []string{relaysTitleStyle.Render(accountsTitle)}
+
+	for i, name := range a.names {
+		marker, style := "  ", relayRowStyle
+		if i == a.cursor {
+			marker, style = "▸ ", relaySelectedStyle
+		}
+		row := style.Render(marker + name)
+		if name == a.current {
+			row += "  " + relayConnectedStyle.Render("● active")
+		}
+		views = append(views, row)
+	}
+
+	if len(a.names) == 1 {
+		views = append(views, "", relayStatsStyle.UnsetPaddingLeft().Width(max(a.w-8, 30)).Render(accountsEmpty))
+	}
+
+	views = append(views, relaysHelpStyle.Render(accountsHelp))
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+func (a *AccountsModal) SetSize(w, h int) {
+	a.w = int((float64(w) * 2) / 3.0)
+}
@@ -1,7 +1,10 @@
 package modal
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"tuistr/components/colors"
 	"tuistr/components/messages"
@@ -19,39 +22,68 @@ type ComposeMode int
 const (
 	ComposePost ComposeMode = iota
 	ComposeReply
+	ComposeEdit
+)
+
+// composeField is the input that has focus in the post composer.
+type composeField int
+
+const (
+	fieldCommunity composeField = iota
+	fieldTitle
+	fieldBody
 )
 
 type ComposeModal struct {
 	textarea       textarea.Model
 	communityInput textinput.Model
+	titleInput     textinput.Model
+	focus          composeField
 	mode           ComposeMode
 	post           model.Post
+	parent         model.Comment
 	contextTitle   string
+	draftKey       string
 	errorMsg       string
 	showCommunity  bool
 	style          lipgloss.Style
-	instructions   string
+	width          int
 }
 
+var (
+	composeHeaderStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text)).Bold(true)
+	composeLabelStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text))
+	composeInfoStyle   = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext))
+	composeErrorStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Red))
+)
+
 func NewComposeModal() ComposeModal {
 	ta := textarea.New()
 	ta.Placeholder = "Write your post..."
 	ta.SetWidth(60)
 	ta.SetHeight(8)
 	ta.ShowLineNumbers = false
-	ta.CharLimit = 1024
+	// long posts are written in $EDITOR, so don't cut them off here
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
 
 	ti := textinput.New()
-	ti.Placeholder = "t:linux"
-	ti.CharLimit = 50
+	ti.Placeholder = "t:linux, u:wss://nos.lol or g:dr5regw3pg"
+	ti.CharLimit = 200
+
+	title := textinput.New()
+	title.Placeholder = "defaults to the first line of the post"
+	title.CharLimit = 200
 
 	return ComposeModal{
 		textarea:       ta,
 		communityInput: ti,
+		titleInput:     title,
+		focus:          fieldBody,
 		mode:           ComposePost,
 		showCommunity:  true,
 		style:          lipgloss.NewStyle(),
-		instructions:   "ctrl+s to publish • esc to cancel",
+		width:          60,
 	}
 }
 
@@ -64,10 +96,25 @@ func (c ComposeModal) Update(msg tea.Msg) (ComposeModal, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
-			return c, messages.ExitModal
+			return c, tea.Batch(c.saveDraft(), messages.ExitModal)
 		case "ctrl+s":
 			return c.submit()
+		case "ctrl+o":
+			return c, c.openEditor()
+		case "tab":
+			return c, c.setFocus(c.nextField(1))
+		case "shift+tab":
+			return c, c.setFocus(c.nextField(-1))
+		}
+
+	case messages.EditorClosedMsg:
+		if msg.Error != "" {
+			c.errorMsg = msg.Error
+			return c, nil
 		}
+		c.textarea.SetValue(msg.Content)
+		c.errorMsg = ""
+		return c, nil
 	}
 
 	var cmds []tea.Cmd
@@ -77,6 +124,10 @@ func (c ComposeModal) Update(msg tea.Msg) (ComposeModal, tea.Cmd) {
 		c.communityInput, cmd = c.communityInput.Update(msg)
 		cmds = append(cmds, cmd)
 	}
+	if c.mode != ComposeReply {
+		c.titleInput, cmd = c.titleInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
 
 	c.textarea, cmd = c.textarea.Update(msg)
 	cmds = append(cmds, cmd)
@@ -85,32 +136,56 @@ func (c ComposeModal) Update(msg tea.Msg) (ComposeModal, tea.Cmd) {
 }
 
 func (c ComposeModal) View() string {
-	header := lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text)).Bold(true).Render(c.contextTitle)
-	bodyView := c.textarea.View()
+	content := []string{composeHeaderStyle.Render(c.contextTitle)}
 
-	var communityView string
 	if c.showCommunity {
-		label := lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text)).Render("community (topic id)")
-		communityView = lipgloss.JoinVertical(lipgloss.Left, label, c.communityInput.View())
+		content = append(content, composeLabelStyle.Render("community (topic id)"), c.communityInput.View())
 	}
+	if c.mode != ComposeReply {
+		content = append(content, composeLabelStyle.Render("title (optional)"), c.titleInput.View())
+	}
+	content = append(content, c.textarea.View())
 
-	info := lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).Render(c.instructions)
-
-	errorView := ""
+	content = append(content, composeInfoStyle.Render(c.help()))
 	if c.errorMsg != "" {
-		errorView = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Red)).Render(c.errorMsg)
+		content = append(content, composeErrorStyle.Render(c.errorMsg))
 	}
 
-	content := []string{header}
-	if communityView != "" {
-		content = append(content, communityView)
+	return c.style.Render(lipgloss.JoinVertical(lipgloss.Left, content...))
+}
+
+func (c ComposeModal) help() string {
+	action := "publish"
+	switch c.mode {
+	case ComposeReply:
+		action = "reply"
+	case ComposeEdit:
+		action = "publish the edit"
 	}
-	content = append(content, bodyView, info)
-	if errorView != "" {
-		content = append(content, errorView)
+	help := fmt.Sprintf("ctrl+s to preview and %s • ctrl+o to open $EDITOR • esc to cancel", action)
+	if len(c.fields()) > 1 {
+		help = "tab next field • " + help
 	}
+	return help
+}
 
-	return c.style.Render(lipgloss.JoinVertical(lipgloss.Left, content...))
+// fields lists the inputs the current mode shows, in tab order.
+func (c ComposeModal) fields() []composeField {
+	switch c.mode {
+	case ComposeReply:
+		return []composeField{fieldBody}
+	case ComposeEdit:
+		return []composeField{fieldTitle, fieldBody}
+	default:
+		return []composeField{fieldCommunity, fieldTitle, fieldBody}
+	}
+}
+
+// nextField returns the field delta steps from the focused one, wrapping around.
+func (c ComposeModal) nextField(delta int) composeField {
+	fields := c.fields()
+	i := max(slices.Index(fields, c.focus), 0)
+	return fields[(i+delta+len(fields))%len(fields)]
 }
 
 func (c *ComposeModal) SetSize(w, h int) {
@@ -120,45 +195,159 @@ func (c *ComposeModal) SetSize(w, h int) {
 	}
 	c.textarea.SetWidth(usableW - 2)
 	c.communityInput.Width = usableW
+	c.titleInput.Width = usableW
+	c.width = usableW
 }
 
 func (c *ComposeModal) Focus() {
-	c.textarea.Focus()
+	c.setFocus(fieldBody)
+}
+
+func (c *ComposeModal) setFocus(field composeField) tea.Cmd {
+	c.focus = field
+	c.communityInput.Blur()
+	c.titleInput.Blur()
+	c.textarea.Blur()
+
+	switch field {
+	case fieldCommunity:
+		return c.communityInput.Focus()
+	case fieldTitle:
+		return c.titleInput.Focus()
+	default:
+		return c.textarea.Focus()
+	}
 }
 
 func (c *ComposeModal) Blur() {
 	c.textarea.Blur()
 	c.communityInput.Blur()
+	c.titleInput.Blur()
 	c.textarea.SetValue("")
 	c.communityInput.SetValue("")
+	c.titleInput.SetValue("")
 	c.errorMsg = ""
 }
 
 func (c *ComposeModal) SetPostContext(community string) {
 	c.mode = ComposePost
 	c.post = model.Post{}
+	c.parent = model.Comment{}
 	c.showCommunity = true
-	c.contextTitle = "New community post (topic only)"
-	c.instructions = "ctrl+s to publish • esc to cancel"
+	c.contextTitle = "New community post"
 	c.errorMsg = ""
+	c.draftKey = model.PostDraftKey(community)
 
-	community = utils.NormalizeCommunity(community)
-	if utils.ValidateTopic(community) {
-		c.communityInput.SetValue(community)
+	if normalized, err := utils.ParseCommunity(community); err == nil {
+		c.communityInput.SetValue(normalized)
 	}
+	c.titleInput.SetValue("")
 	c.textarea.SetValue("")
 }
 
-func (c *ComposeModal) SetReplyContext(post model.Post) {
+func (c *ComposeModal) SetReplyContext(post model.Post, parent model.Comment) {
 	c.mode = ComposeReply
 	c.post = post
+	c.parent = parent
 	c.showCommunity = false
 	c.contextTitle = fmt.Sprintf("Reply to %s", strings.TrimSpace(post.PostTitle))
-	c.instructions = "ctrl+s to reply • esc to cancel"
+	if parent.ID != "" {
+		c.contextTitle = fmt.Sprintf("Reply to %s in %s", parent.Author, strings.TrimSpace(post.PostTitle))
+	}
 	c.errorMsg = ""
+	c.draftKey = model.ReplyDraftKey(post, parent)
 	c.textarea.SetValue("")
 }
 
+// SetEditContext opens one of the user's own posts for a revision. The community
+// stays as it was; the title and text start from the current version.
+func (c *ComposeModal) SetEditContext(post model.Post) {
+	c.mode = ComposeEdit
+	c.post = post
+	c.parent = model.Comment{}
+	c.showCommunity = false
+	c.contextTitle = fmt.Sprintf("Edit %s", strings.TrimSpace(post.PostTitle))
+	c.errorMsg = ""
+	c.draftKey = model.EditDraftKey(post)
+	c.titleInput.SetValue(post.Subject)
+	c.textarea.SetValue(post.Content)
+}
+
+// restoreDraft puts a saved draft back into the composer. The context must
+// already be set for the draft's target.
+func (c *ComposeModal) restoreDraft(draft model.Draft) {
+	if draft.Key == "" {
+		return
+	}
+
+	c.draftKey = draft.Key
+	if c.showCommunity && draft.Community != "" {
+		c.communityInput.SetValue(draft.Community)
+	}
+	if c.mode != ComposeReply {
+		c.titleInput.SetValue(draft.Subject)
+	}
+	c.textarea.SetValue(draft.Content)
+}
+
+func (c ComposeModal) draft() model.Draft {
+	draft := model.Draft{Key: c.draftKey, Content: c.textarea.Value()}
+	switch c.mode {
+	case ComposePost:
+		draft.Community = strings.TrimSpace(c.communityInput.Value())
+		draft.Subject = strings.TrimSpace(c.titleInput.Value())
+	case ComposeEdit:
+		draft.Post, draft.Edit = c.post, true
+		draft.Subject = strings.TrimSpace(c.titleInput.Value())
+	default:
+		draft.Post, draft.Parent = c.post, c.parent
+	}
+	return draft
+}
+
+// saveDraft keeps the text so far; blank text drops the draft.
+func (c ComposeModal) saveDraft() tea.Cmd {
+	draft := c.draft()
+	return func() tea.Msg {
+		return messages.SaveDraftMsg(draft)
+	}
+}
+
+// openEditor suspends the program and opens the text so far in $EDITOR. The
+// saved file replaces the text when the editor exits.
+func (c ComposeModal) openEditor() tea.Cmd {
+	file, err := os.CreateTemp("", "tuistr-*.md")
+	if err != nil {
+		return editorClosed(fmt.Errorf("could not create temp file: %w", err))
+	}
+	path := file.Name()
+
+	_, err = file.WriteString(c.textarea.Value())
+	if err = errors.Join(err, file.Close()); err != nil {
+		os.Remove(path)
+		return editorClosed(fmt.Errorf("could not write temp file: %w", err))
+	}
+
+	return tea.ExecProcess(utils.EditorCommand(path), func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return messages.EditorClosedMsg{Error: fmt.Sprintf("editor failed: %v", err)}
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return messages.EditorClosedMsg{Error: fmt.Sprintf("could not read temp file: %v", err)}
+		}
+		return messages.EditorClosedMsg{Content: strings.TrimRight(string(data), "\n")}
+	})
+}
+
+func editorClosed(err error) tea.Cmd {
+	return func() tea.Msg {
+		return messages.EditorClosedMsg{Error: err.Error()}
+	}
+}
+
 func (c *ComposeModal) submit() (ComposeModal, tea.Cmd) {
 	content := strings.TrimSpace(c.textarea.Value())
 	if content == "" {
@@ -166,18 +355,27 @@ func (c *ComposeModal) submit() (ComposeModal, tea.Cmd) {
 		return *c, nil
 	}
 
+	if c.mode == ComposeEdit {
+		title := strings.TrimSpace(c.titleInput.Value())
+		return *c, tea.Batch(c.saveDraft(), func() tea.Msg {
+			return messages.SubmitEditMsg{Post: c.post, Title: title, Content: content, DraftKey: c.draftKey}
+		})
+	}
+
 	if c.mode == ComposePost {
-		community := strings.TrimSpace(c.communityInput.Value())
-		if !utils.ValidateTopic(community) {
-			c.errorMsg = "community must be a topic like t:linux"
+		community, err := utils.ParseCommunity(c.communityInput.Value())
+		if err != nil {
+			c.errorMsg = err.Error()
 			return *c, nil
 		}
-		return *c, func() tea.Msg {
-			return messages.SubmitPostMsg{Community: community, Content: content}
-		}
+		title := strings.TrimSpace(c.titleInput.Value())
+		// saved first so a failed publish doesn't lose the text
+		return *c, tea.Batch(c.saveDraft(), func() tea.Msg {
+			return messages.SubmitPostMsg{Community: community, Title: title, Content: content, DraftKey: c.draftKey}
+		})
 	}
 
-	return *c, func() tea.Msg {
-		return messages.SubmitReplyMsg{Post: c.post, Content: content}
-	}
+	return *c, tea.Batch(c.saveDraft(), func() tea.Msg {
+		return messages.SubmitReplyMsg{Post: c.post, Parent: c.parent, Content: content, DraftKey: c.draftKey}
+	})
 }
@@ -0,0 +1,48 @@
+package modal
+
+import (
+	"fmt"
+	"tuistr/components/messages"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ConfirmModal asks a yes/no question and sends confirm on yes.
+type ConfirmModal struct {
+	question string
+	confirm  tea.Msg
+}
+
+func NewConfirmModal() ConfirmModal {
+	return ConfirmModal{}
+}
+
+func (c *ConfirmModal) Open(question string, confirm tea.Msg) {
+	c.question = question
+	c.confirm = confirm
+}
+
+func (c *ConfirmModal) Blur() {
+	c.question = ""
+	c.confirm = nil
+}
+
+func (c ConfirmModal) View() string {
+	titleView := quitTitleStyle.Render(c.question)
+	yesNoView := quitYesNoStyle.Render(yesNoMsg)
+	return fmt.Sprintf("%s  %s", titleView, yesNoView)
+}
+
+func (c ConfirmModal) Update(msg tea.Msg) (ConfirmModal, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "y", "Y":
+			confirm := c.confirm
+			return c, func() tea.Msg { return confirm }
+		default:
+			return c, messages.ExitModal
+		}
+	}
+
+	return c, nil
+}
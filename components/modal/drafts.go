@@ -0,0 +1,101 @@
+package modal
+
+import (
+	"strings"
+	"tuistr/components/messages"
+	"tuistr/model"
+	"tuistr/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	draftsTitle = "Drafts"
+	draftsHelp  = "j/k move • enter open • x delete • esc close"
+	draftsEmpty = "No drafts. Text left in the composer is kept here until it is published."
+)
+
+// DraftsModal lists saved drafts and reopens them in the composer.
+type DraftsModal struct {
+	drafts []model.Draft
+	cursor int
+	w      int
+}
+
+func NewDraftsModal() DraftsModal {
+	return DraftsModal{}
+}
+
+func (d DraftsModal) Init() tea.Cmd {
+	return nil
+}
+
+func (d *DraftsModal) Open() tea.Cmd {
+	d.drafts = nil
+	d.cursor = 0
+	return messages.LoadDrafts
+}
+
+func (d DraftsModal) Update(msg tea.Msg) (DraftsModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case messages.DraftsMsg:
+		d.drafts = msg
+		d.cursor = utils.Clamp(0, max(len(d.drafts)-1, 0), d.cursor)
+		return d, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			d.cursor = utils.Clamp(0, max(len(d.drafts)-1, 0), d.cursor+1)
+		case "k", "up":
+			d.cursor = utils.Clamp(0, max(len(d.drafts)-1, 0), d.cursor-1)
+		case "enter":
+			if len(d.drafts) == 0 {
+				return d, nil
+			}
+			draft := d.drafts[d.cursor]
+			return d, func() tea.Msg { return messages.OpenDraftMsg(draft) }
+		case "x":
+			if len(d.drafts) == 0 {
+				return d, nil
+			}
+			key := d.drafts[d.cursor].Key
+			return d, func() tea.Msg { return messages.DeleteDraftMsg(key) }
+		case "esc", "q":
+			return d, messages.ExitModal
+		}
+	}
+
+	return d, nil
+}
+
+func (d DraftsModal) View() string {
+	views := []string{relaysTitleStyle.Render(draftsTitle)}
+
+	width := max(d.w-8, 30)
+	for i, draft := range d.drafts {
+		marker, style := "  ", relayRowStyle
+		if i == d.cursor {
+			marker, style = "▸ ", relaySelectedStyle
+		}
+		row := style.Render(marker+draft.Title()) + "  " + relayStatsStyle.UnsetPaddingLeft().Render(utils.FriendlyTime(draft.UpdatedAt))
+		views = append(views, row, relayStatsStyle.Render(utils.TruncateString(firstLine(draft.Content), width)))
+	}
+
+	if len(d.drafts) == 0 {
+		views = append(views, "", relayStatsStyle.UnsetPaddingLeft().Width(width).Render(draftsEmpty))
+	}
+
+	views = append(views, relaysHelpStyle.Render(draftsHelp))
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+func (d *DraftsModal) SetSize(w, h int) {
+	d.w = int((float64(w) * 2) / 3.0)
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
@@ -19,6 +19,13 @@ const (
 	quitting
 	showingError
 	composing
+	showingRelays
+	managingRelays
+	unlocking
+	switchingAccounts
+	browsingDrafts
+	previewingPublish
+	confirming
 )
 
 var modalStyle = lipgloss.NewStyle().
@@ -33,9 +40,18 @@ type ModalManager struct {
 	spinner    SpinnerModal
 	errorModal ErrorModal
 	composer   ComposeModal
+	relays     RelaysModal
+	relayMgr   RelayManagerModal
+	passphrase PassphraseModal
+	accounts   AccountsModal
+	drafts     DraftsModal
+	preview    PublishPreviewModal
+	confirm    ConfirmModal
 	state      SessionState
-	style      lipgloss.Style
-	onClose    tea.Cmd
+	// where to go back to when the passphrase prompt interrupted the composer
+	unlockReturn SessionState
+	style        lipgloss.Style
+	onClose      tea.Cmd
 }
 
 func NewModalManager() ModalManager {
@@ -45,6 +61,13 @@ func NewModalManager() ModalManager {
 		spinner:    NewSpinnerModal(),
 		errorModal: NewErrorModal(),
 		composer:   NewComposeModal(),
+		relays:     NewRelaysModal(),
+		relayMgr:   NewRelayManagerModal(),
+		passphrase: NewPassphraseModal(),
+		accounts:   NewAccountsModal(),
+		drafts:     NewDraftsModal(),
+		preview:    NewPublishPreviewModal(),
+		confirm:    NewConfirmModal(),
 		style:      modalStyle,
 	}
 }
@@ -93,6 +116,14 @@ func (m ModalManager) handleGlobalMessages(msg tea.Msg) (ModalManager, tea.Cmd)
 			return m, m.SetQuitting()
 		case "s", "S":
 			return m, m.SetSearching()
+		case "R":
+			return m, m.SetRelays()
+		case "M":
+			return m, m.SetRelayManager()
+		case "A":
+			return m, m.SetAccounts()
+		case "D":
+			return m, m.SetDrafts()
 		}
 	}
 
@@ -118,11 +149,66 @@ func (m ModalManager) handleFocusedMessages(msg tea.Msg) (ModalManager, tea.Cmd)
 	case composing:
 		m.composer, cmd = m.composer.Update(msg)
 		return m, cmd
+	case showingRelays:
+		m.relays, cmd = m.relays.Update(msg)
+		return m, cmd
+	case managingRelays:
+		m.relayMgr, cmd = m.relayMgr.Update(msg)
+		return m, cmd
+	case unlocking:
+		return m.updateUnlocking(msg)
+	case switchingAccounts:
+		m.accounts, cmd = m.accounts.Update(msg)
+		return m, cmd
+	case browsingDrafts:
+		m.drafts, cmd = m.drafts.Update(msg)
+		return m, cmd
+	case confirming:
+		m.confirm, cmd = m.confirm.Update(msg)
+		return m, cmd
+	case previewingPublish:
+		// going back keeps the composer as it was
+		if msg, ok := msg.(tea.KeyMsg); ok && (msg.String() == "esc" || msg.String() == "n") {
+			m.preview.Blur()
+			m.state = composing
+			return m, nil
+		}
+		m.preview, cmd = m.preview.Update(msg)
+		return m, cmd
 	default:
 		return m, nil
 	}
 }
 
+func (m ModalManager) updateUnlocking(msg tea.Msg) (ModalManager, tea.Cmd) {
+	switch msg := msg.(type) {
+	case messages.SignerUnlockedMsg:
+		if msg.Error != "" {
+			break
+		}
+
+		retry := func() tea.Msg { return msg.Retry }
+		m.passphrase.Blur()
+		if m.unlockReturn == composing {
+			m.state = composing
+			return m, retry
+		}
+		return m, tea.Batch(messages.ExitModal, retry)
+
+	case tea.KeyMsg:
+		// cancelling goes back to the draft instead of throwing it away
+		if msg.String() == "esc" && m.unlockReturn == composing && !m.passphrase.unlocking {
+			m.passphrase.Blur()
+			m.state = composing
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.passphrase, cmd = m.passphrase.Update(msg)
+	return m, cmd
+}
+
 func (m ModalManager) View(background Viewer) string {
 	switch m.state {
 	case loading:
@@ -135,6 +221,20 @@ func (m ModalManager) View(background Viewer) string {
 		return PlaceModal(m.errorModal, background, lipgloss.Center, lipgloss.Center, m.style)
 	case composing:
 		return PlaceModal(m.composer, background, lipgloss.Center, lipgloss.Center, m.style)
+	case showingRelays:
+		return PlaceModal(m.relays, background, lipgloss.Center, lipgloss.Center, m.style)
+	case managingRelays:
+		return PlaceModal(m.relayMgr, background, lipgloss.Center, lipgloss.Center, m.style)
+	case unlocking:
+		return PlaceModal(m.passphrase, background, lipgloss.Center, lipgloss.Center, m.style)
+	case switchingAccounts:
+		return PlaceModal(m.accounts, background, lipgloss.Center, lipgloss.Center, m.style)
+	case browsingDrafts:
+		return PlaceModal(m.drafts, background, lipgloss.Center, lipgloss.Center, m.style)
+	case confirming:
+		return PlaceModal(m.confirm, background, lipgloss.Center, lipgloss.Center, m.style)
+	case previewingPublish:
+		return PlaceModal(m.preview, background, lipgloss.Center, lipgloss.Center, m.style)
 	default:
 		// This sometimes happens when loading completes before the loading modal finishes rendering
 		return ""
@@ -144,6 +244,12 @@ func (m ModalManager) View(background Viewer) string {
 func (m *ModalManager) SetSize(w, h int) {
 	m.search.SetSize(w, h)
 	m.composer.SetSize(w, h)
+	m.relays.SetSize(w, h)
+	m.relayMgr.SetSize(w, h)
+	m.passphrase.SetSize(w, h)
+	m.accounts.SetSize(w, h)
+	m.drafts.SetSize(w, h)
+	m.preview.SetSize(w, h)
 
 	modalSize := int((float64(w) * (2)) / 3.0)
 	m.style = m.style.MaxWidth(modalSize)
@@ -153,6 +259,12 @@ func (m *ModalManager) Blur() tea.Cmd {
 	m.state = defaultState
 	m.search.Blur()
 	m.composer.Blur()
+	m.relays.Blur()
+	m.relayMgr.Blur()
+	m.passphrase.Blur()
+	m.preview.Blur()
+	m.confirm.Blur()
+	m.unlockReturn = defaultState
 
 	onClose := m.onClose
 	m.onClose = nil
@@ -189,16 +301,76 @@ func (m *ModalManager) SetErrorWithCallback(errorMsg string, onClose tea.Cmd) te
 	return messages.OpenModal
 }
 
-func (m *ModalManager) SetComposePost(community string) tea.Cmd {
+func (m *ModalManager) SetRelays() tea.Cmd {
+	m.state = showingRelays
+	return tea.Batch(messages.OpenModal, m.relays.Open())
+}
+
+func (m *ModalManager) SetRelayManager() tea.Cmd {
+	m.state = managingRelays
+	return tea.Batch(messages.OpenModal, m.relayMgr.Open())
+}
+
+func (m *ModalManager) SetAccounts() tea.Cmd {
+	m.state = switchingAccounts
+	return tea.Batch(messages.OpenModal, m.accounts.Open())
+}
+
+func (m *ModalManager) SetDrafts() tea.Cmd {
+	m.state = browsingDrafts
+	return tea.Batch(messages.OpenModal, m.drafts.Open())
+}
+
+// SetConfirm asks question and sends confirm if the user agrees.
+func (m *ModalManager) SetConfirm(question string, confirm tea.Msg) tea.Cmd {
+	m.state = confirming
+	m.confirm.Open(question, confirm)
+	return messages.OpenModal
+}
+
+// SetPublishPreview asks to confirm the previewed event. A preview that could
+// not be built goes back to the composer with the error.
+func (m *ModalManager) SetPublishPreview(msg messages.PublishPreviewMsg) tea.Cmd {
+	if msg.Error != "" {
+		m.state = composing
+		m.composer.errorMsg = msg.Error
+		return nil
+	}
+
+	m.state = previewingPublish
+	m.preview.Open(msg.Preview, msg.Confirm)
+	return nil
+}
+
+// SetUnlocking asks for the secret key's passphrase, then sends retry again.
+func (m *ModalManager) SetUnlocking(retry tea.Msg) tea.Cmd {
+	m.unlockReturn = m.state
+	m.state = unlocking
+	return tea.Batch(messages.OpenModal, m.passphrase.Open(retry))
+}
+
+// SetComposePost opens the composer for a new post, restoring draft if it has one.
+func (m *ModalManager) SetComposePost(community string, draft model.Draft) tea.Cmd {
 	m.state = composing
 	m.composer.SetPostContext(community)
+	m.composer.restoreDraft(draft)
+	m.composer.Focus()
+	return messages.OpenModal
+}
+
+// SetComposeEdit opens the composer on a revision of post, restoring draft if it has one.
+func (m *ModalManager) SetComposeEdit(post model.Post, draft model.Draft) tea.Cmd {
+	m.state = composing
+	m.composer.SetEditContext(post)
+	m.composer.restoreDraft(draft)
 	m.composer.Focus()
 	return messages.OpenModal
 }
 
-func (m *ModalManager) SetComposeReply(post model.Post) tea.Cmd {
+func (m *ModalManager) SetComposeReply(post model.Post, parent model.Comment, draft model.Draft) tea.Cmd {
 	m.state = composing
-	m.composer.SetReplyContext(post)
+	m.composer.SetReplyContext(post, parent)
+	m.composer.restoreDraft(draft)
 	m.composer.Focus()
 	return messages.OpenModal
 }
@@ -0,0 +1,116 @@
+package modal
+
+import (
+	"tuistr/components/colors"
+	"tuistr/components/messages"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	passphraseTitle = "Unlock secret key"
+	passphraseText  = "Your secret key is encrypted (NIP-49). Enter its passphrase to sign; it stays unlocked until you quit."
+	passphraseHelp  = "enter to unlock • esc to cancel"
+)
+
+var passphraseTextStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text))
+
+// PassphraseModal asks for the passphrase of an ncryptsec secret key, then hands
+// the interrupted publish back to be retried.
+type PassphraseModal struct {
+	input     textinput.Model
+	retry     tea.Msg
+	unlocking bool
+	errorMsg  string
+	w         int
+}
+
+func NewPassphraseModal() PassphraseModal {
+	input := textinput.New()
+	input.Placeholder = "passphrase"
+	input.EchoMode = textinput.EchoPassword
+	input.EchoCharacter = '•'
+	input.CharLimit = 256
+
+	return PassphraseModal{input: input}
+}
+
+func (p PassphraseModal) Init() tea.Cmd {
+	return nil
+}
+
+// Open prompts for the passphrase; retry is sent again once the key is unlocked.
+func (p *PassphraseModal) Open(retry tea.Msg) tea.Cmd {
+	p.retry = retry
+	p.unlocking = false
+	p.errorMsg = ""
+	p.input.SetValue("")
+	return p.input.Focus()
+}
+
+func (p *PassphraseModal) Blur() {
+	p.retry = nil
+	p.unlocking = false
+	p.input.Blur()
+	p.input.SetValue("")
+}
+
+func (p PassphraseModal) Update(msg tea.Msg) (PassphraseModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case messages.SignerUnlockedMsg:
+		p.unlocking = false
+		p.input.SetValue("")
+		p.errorMsg = msg.Error
+		return p, nil
+
+	case tea.KeyMsg:
+		if p.unlocking {
+			return p, nil
+		}
+
+		switch msg.String() {
+		case "esc":
+			return p, messages.ExitModal
+		case "enter":
+			passphrase, retry := p.input.Value(), p.retry
+			if passphrase == "" {
+				return p, nil
+			}
+			p.unlocking = true
+			p.errorMsg = ""
+			return p, func() tea.Msg {
+				return messages.UnlockSignerMsg{Passphrase: passphrase, Retry: retry}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return p, cmd
+}
+
+func (p PassphraseModal) View() string {
+	views := []string{
+		relaysTitleStyle.Render(passphraseTitle),
+		passphraseTextStyle.Width(max(p.w-8, 30)).Render(passphraseText),
+		"",
+		p.input.View(),
+	}
+
+	switch {
+	case p.unlocking:
+		views = append(views, "", relayNoticeStyle.Render("unlocking..."))
+	case p.errorMsg != "":
+		views = append(views, "", defaultErrorStyle.Render(p.errorMsg))
+	}
+
+	views = append(views, relaysHelpStyle.Render(passphraseHelp))
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+func (p *PassphraseModal) SetSize(w, h int) {
+	p.w = int((float64(w) * 2) / 3.0)
+	p.input.Width = max(min(p.w-10, 40), 20)
+}
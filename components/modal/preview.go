@@ -0,0 +1,103 @@
+package modal
+
+import (
+	"strings"
+	"tuistr/components/comments"
+	"tuistr/model"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	previewTitle     = "Publish this?"
+	previewHelp      = "enter to publish • j/k scroll • esc to keep editing"
+	previewNoRelays  = "no write relays are enabled"
+	minPreviewWidth  = 30
+	minPreviewHeight = 5
+)
+
+// PublishPreviewModal shows an event as the thread view will, with its tags and
+// target relays, and only publishes it once confirmed.
+type PublishPreviewModal struct {
+	viewport viewport.Model
+	preview  model.PublishPreview
+	confirm  tea.Msg
+	w, h     int
+}
+
+func NewPublishPreviewModal() PublishPreviewModal {
+	return PublishPreviewModal{viewport: viewport.New(minPreviewWidth, minPreviewHeight)}
+}
+
+func (p PublishPreviewModal) Init() tea.Cmd {
+	return nil
+}
+
+// Open shows preview; confirm is sent when the user accepts it.
+func (p *PublishPreviewModal) Open(preview model.PublishPreview, confirm tea.Msg) {
+	p.preview = preview
+	p.confirm = confirm
+	p.setContent()
+	p.viewport.GotoTop()
+}
+
+func (p *PublishPreviewModal) Blur() {
+	p.preview = model.PublishPreview{}
+	p.confirm = nil
+}
+
+func (p PublishPreviewModal) Update(msg tea.Msg) (PublishPreviewModal, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "enter", "y", "ctrl+s":
+			confirm := p.confirm
+			return p, func() tea.Msg { return confirm }
+		}
+	}
+
+	var cmd tea.Cmd
+	p.viewport, cmd = p.viewport.Update(msg)
+	return p, cmd
+}
+
+func (p PublishPreviewModal) View() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		relaysTitleStyle.Render(previewTitle),
+		p.viewport.View(),
+		relaysHelpStyle.Render(previewHelp))
+}
+
+func (p *PublishPreviewModal) SetSize(w, h int) {
+	p.w = max(int(float64(w)*2/3*maxModalWidthPercentage)-modalStyle.GetHorizontalFrameSize(), minPreviewWidth)
+	// leave room for the modal frame, title and help
+	p.h = max(h-modalStyle.GetVerticalFrameSize()-6, minPreviewHeight)
+	p.setContent()
+}
+
+func (p *PublishPreviewModal) setContent() {
+	var tags strings.Builder
+	for _, tag := range p.preview.Tags {
+		tags.WriteString(strings.Join(tag, " "))
+		tags.WriteString("\n")
+	}
+
+	relays := strings.Join(p.preview.Relays, "\n")
+	if relays == "" {
+		relays = relayErrorStyle.UnsetPaddingLeft().Render(previewNoRelays)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		comments.RenderThread(p.preview.Thread, p.w),
+		"",
+		relaysTitleStyle.Render("Tags"),
+		relayStatsStyle.Width(p.w).Render(strings.TrimRight(tags.String(), "\n")),
+		"",
+		relaysTitleStyle.Render("Relays"),
+		relayStatsStyle.Width(p.w).Render(relays))
+
+	p.viewport.Width = p.w
+	p.viewport.Height = min(p.h, lipgloss.Height(content))
+	p.viewport.SetContent(content)
+}
@@ -0,0 +1,369 @@
+package modal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"tuistr/components/colors"
+	"tuistr/components/messages"
+	"tuistr/config"
+	"tuistr/model"
+	"tuistr/utils"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	relayManagerTitle   = "Relay manager"
+	relayManagerHelp    = "j/k move • a add • x remove • r/w toggle read/write • t test • i info • ctrl+s save • esc close"
+	relayManagerAddHelp = "enter to add • esc to cancel"
+	relayManagerDirty   = "unsaved changes — esc again to discard"
+)
+
+var (
+	relayFlagOnStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Green)).Bold(true)
+	relayFlagOffStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext))
+	relayNoticeStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Yellow)).Italic(true)
+	relayInfoStyle    = lipgloss.NewStyle().
+				Foreground(colors.AdaptiveColor(colors.Text)).
+				Border(lipgloss.NormalBorder(), true, false, false, false).
+				BorderForeground(colors.AdaptiveColor(colors.Subtext)).
+				MarginTop(1)
+)
+
+type managedRelay struct {
+	config.RelayMode
+	test string
+	info *model.RelayInfo
+}
+
+// RelayManagerModal edits the relays in the config file.
+type RelayManagerModal struct {
+	relays     []managedRelay
+	cursor     int
+	adding     bool
+	input      textinput.Model
+	dirty      bool
+	confirming bool
+	notice     string
+	errorMsg   string
+	w          int
+}
+
+func NewRelayManagerModal() RelayManagerModal {
+	input := textinput.New()
+	input.Placeholder = "wss://relay.example.com"
+	input.CharLimit = 200
+
+	return RelayManagerModal{input: input}
+}
+
+func (r RelayManagerModal) Init() tea.Cmd {
+	return nil
+}
+
+// Open clears the previous session and loads the relays from the config file.
+func (r *RelayManagerModal) Open() tea.Cmd {
+	r.relays = nil
+	r.cursor = 0
+	r.dirty = false
+	r.confirming = false
+	r.notice = "loading config..."
+	r.errorMsg = ""
+	r.Blur()
+	return messages.LoadRelayConfig
+}
+
+func (r *RelayManagerModal) Blur() {
+	r.adding = false
+	r.input.Blur()
+	r.input.SetValue("")
+}
+
+func (r RelayManagerModal) Update(msg tea.Msg) (RelayManagerModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case messages.RelayConfigMsg:
+		r.relays = nil
+		for _, mode := range msg.Relays {
+			r.relays = append(r.relays, managedRelay{RelayMode: mode})
+		}
+		r.notice, r.errorMsg = "", msg.Error
+		return r, nil
+
+	case messages.RelayConfigSavedMsg:
+		if msg.Error != "" {
+			r.notice, r.errorMsg = "", msg.Error
+			return r, nil
+		}
+		r.dirty = false
+		r.notice, r.errorMsg = "saved", ""
+		return r, nil
+
+	case messages.RelayTestedMsg:
+		if i := r.index(msg.URL); i >= 0 {
+			r.relays[i].test = fmt.Sprintf("ok %dms", msg.Latency.Milliseconds())
+			if msg.Error != "" {
+				r.relays[i].test = "failed: " + msg.Error
+			}
+		}
+		return r, nil
+
+	case messages.RelayInfoMsg:
+		if i := r.index(msg.URL); i >= 0 {
+			if msg.Error != "" {
+				r.relays[i].test = "no NIP-11 info: " + msg.Error
+			} else {
+				info := msg.Info
+				r.relays[i].info = &info
+			}
+		}
+		return r, nil
+
+	case tea.KeyMsg:
+		if r.adding {
+			return r.updateAdding(msg)
+		}
+		return r.updateKeys(msg)
+	}
+
+	return r, nil
+}
+
+func (r RelayManagerModal) updateKeys(msg tea.KeyMsg) (RelayManagerModal, tea.Cmd) {
+	keypress := msg.String()
+	if keypress != "esc" {
+		r.confirming = false
+	}
+
+	switch keypress {
+	case "j", "down":
+		r.cursor = utils.Clamp(0, max(len(r.relays)-1, 0), r.cursor+1)
+	case "k", "up":
+		r.cursor = utils.Clamp(0, max(len(r.relays)-1, 0), r.cursor-1)
+
+	case "a":
+		r.adding = true
+		r.errorMsg = ""
+		return r, r.input.Focus()
+
+	case "x", "delete":
+		if len(r.relays) > 0 {
+			r.relays = slices.Delete(r.relays, r.cursor, r.cursor+1)
+			r.cursor = utils.Clamp(0, max(len(r.relays)-1, 0), r.cursor)
+			r.dirty = true
+		}
+
+	case "r", "w":
+		if len(r.relays) > 0 {
+			relay := &r.relays[r.cursor]
+			if keypress == "r" {
+				relay.Read = !relay.Read
+			} else {
+				relay.Write = !relay.Write
+			}
+			r.dirty = true
+		}
+
+	case "t":
+		if len(r.relays) > 0 {
+			url := r.relays[r.cursor].URL
+			r.relays[r.cursor].test = "testing..."
+			return r, func() tea.Msg { return messages.TestRelayMsg(url) }
+		}
+
+	case "i":
+		if len(r.relays) > 0 {
+			url := r.relays[r.cursor].URL
+			return r, func() tea.Msg { return messages.FetchRelayInfoMsg(url) }
+		}
+
+	case "ctrl+s":
+		return r.save()
+
+	case "esc", "q":
+		if r.dirty && !r.confirming {
+			r.confirming = true
+			return r, nil
+		}
+		return r, messages.ExitModal
+	}
+
+	return r, nil
+}
+
+func (r RelayManagerModal) updateAdding(msg tea.KeyMsg) (RelayManagerModal, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		r.Blur()
+		return r, nil
+
+	case "enter":
+		url := strings.TrimSpace(r.input.Value())
+		lower := strings.ToLower(url)
+		if !strings.HasPrefix(lower, "wss://") && !strings.HasPrefix(lower, "ws://") {
+			r.errorMsg = "relay url must start with ws:// or wss://"
+			return r, nil
+		}
+
+		r.Blur()
+		r.errorMsg = ""
+		if i := r.index(url); i >= 0 {
+			r.cursor = i
+			return r, nil
+		}
+
+		r.relays = append(r.relays, managedRelay{RelayMode: config.RelayMode{URL: url, Read: true, Write: true}, test: "testing..."})
+		r.cursor = len(r.relays) - 1
+		r.dirty = true
+		return r, func() tea.Msg { return messages.TestRelayMsg(url) }
+	}
+
+	var cmd tea.Cmd
+	r.input, cmd = r.input.Update(msg)
+	return r, cmd
+}
+
+func (r RelayManagerModal) save() (RelayManagerModal, tea.Cmd) {
+	modes := make([]config.RelayMode, 0, len(r.relays))
+	readers := 0
+	for _, relay := range r.relays {
+		if relay.Read {
+			readers++
+		}
+		modes = append(modes, relay.RelayMode)
+	}
+
+	if readers == 0 {
+		r.errorMsg = "at least one relay must be read from"
+		return r, nil
+	}
+
+	r.notice, r.errorMsg = "saving...", ""
+	return r, func() tea.Msg { return messages.SaveRelayConfigMsg(modes) }
+}
+
+func (r RelayManagerModal) index(url string) int {
+	return slices.IndexFunc(r.relays, func(relay managedRelay) bool {
+		return strings.EqualFold(relay.URL, url)
+	})
+}
+
+func (r RelayManagerModal) View() string {
+	views := []string{relaysTitleStyle.Render(relayManagerTitle)}
+
+	if len(r.relays) == 0 && r.notice == "" {
+		views = append(views, relayRowStyle.Render("No relays. Press a to add one."))
+	}
+
+	for i, relay := range r.relays {
+		views = append(views, r.relayView(relay, i == r.cursor))
+	}
+
+	if len(r.relays) > 0 && r.relays[r.cursor].info != nil {
+		views = append(views, r.infoView(*r.relays[r.cursor].info))
+	}
+
+	if r.adding {
+		views = append(views, "", r.input.View(), relaysHelpStyle.Render(relayManagerAddHelp))
+	}
+
+	switch {
+	case r.errorMsg != "":
+		views = append(views, "", defaultErrorStyle.Render(r.errorMsg))
+	case r.confirming:
+		views = append(views, "", relayNoticeStyle.Render(relayManagerDirty))
+	case r.notice != "":
+		views = append(views, "", relayNoticeStyle.Render(r.notice))
+	case r.dirty:
+		views = append(views, "", relayNoticeStyle.Render("unsaved changes"))
+	}
+
+	if !r.adding {
+		views = append(views, relaysHelpStyle.Render(relayManagerHelp))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+func (r RelayManagerModal) relayView(relay managedRelay, selected bool) string {
+	flag := func(name string, on bool) string {
+		if on {
+			return relayFlagOnStyle.Render(name)
+		}
+		return relayFlagOffStyle.Render("-")
+	}
+
+	marker, rowStyle := "  ", relayRowStyle
+	if selected {
+		marker, rowStyle = "▸ ", relaySelectedStyle
+	}
+
+	row := fmt.Sprintf("%s%s  %s", flag("R", relay.Read), flag("W", relay.Write), rowStyle.Render(marker+relay.URL))
+	if relay.info != nil && relay.info.Name != "" {
+		row += relayStatsStyle.Render(relay.info.Name)
+	}
+	if relay.test != "" {
+		row += relayStatsStyle.Render(utils.TruncateString(relay.test, max(r.w/2, 20)))
+	}
+	return row
+}
+
+func (r RelayManagerModal) infoView(info model.RelayInfo) string {
+	var lines []string
+	if info.Name != "" {
+		lines = append(lines, info.Name)
+	}
+	if info.Description != "" {
+		lines = append(lines, utils.TruncateString(info.Description, max(r.w-8, 20)))
+	}
+	if info.Software != "" {
+		lines = append(lines, "software: "+info.Software)
+	}
+
+	if len(info.SupportedNIPs) > 0 {
+		nips := make([]string, 0, len(info.SupportedNIPs))
+		for _, nip := range info.SupportedNIPs {
+			nips = append(nips, fmt.Sprint(nip))
+		}
+		lines = append(lines, "NIPs: "+strings.Join(nips, ", "))
+	}
+
+	var limits []string
+	if info.MaxLimit > 0 {
+		limits = append(limits, fmt.Sprintf("max limit %d", info.MaxLimit))
+	}
+	if info.MaxSubscriptions > 0 {
+		limits = append(limits, fmt.Sprintf("max subscriptions %d", info.MaxSubscriptions))
+	}
+	if info.MaxMessageLength > 0 {
+		limits = append(limits, fmt.Sprintf("max message %d bytes", info.MaxMessageLength))
+	}
+	if info.MaxContentLength > 0 {
+		limits = append(limits, fmt.Sprintf("max content %d chars", info.MaxContentLength))
+	}
+	if info.AuthRequired {
+		limits = append(limits, "auth required")
+	}
+	if info.PaymentRequired {
+		limits = append(limits, "payment required")
+	}
+	if info.RestrictedWrites {
+		limits = append(limits, "restricted writes")
+	}
+	if len(limits) > 0 {
+		lines = append(lines, "limits: "+strings.Join(limits, " • "))
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, "The relay published an empty NIP-11 document.")
+	}
+
+	return relayInfoStyle.Width(max(r.w-8, 20)).Render(strings.Join(lines, "\n"))
+}
+
+func (r *RelayManagerModal) SetSize(w, h int) {
+	r.w = int((float64(w) * 2) / 3.0)
+	r.input.Width = max(r.w-10, 20)
+}
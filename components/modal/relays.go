@@ -0,0 +1,235 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"tuistr/components/colors"
+	"tuistr/components/messages"
+	"tuistr/model"
+	"tuistr/utils"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	relaysTitle        = "Relays"
+	relaysHelp         = "j/k move • r reconnect • d disable/enable • t allow auth • a add relay • esc close"
+	relaysAddHelp      = "enter to add for this session • esc to cancel"
+	relayRefreshPeriod = 2 * time.Second
+)
+
+var (
+	relaysTitleStyle    = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text)).Bold(true).MarginBottom(1)
+	relaysHelpStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).Italic(true).MarginTop(1)
+	relayRowStyle       = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Text))
+	relaySelectedStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Purple)).Bold(true)
+	relayStatsStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext)).PaddingLeft(4)
+	relayErrorStyle     = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Red)).PaddingLeft(4)
+	relayConnectedStyle = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Green))
+	relayDownStyle      = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Red))
+	relayDisabledStyle  = lipgloss.NewStyle().Foreground(colors.AdaptiveColor(colors.Subtext))
+)
+
+// RelaysModal shows the health of each relay and lets the user reconnect, disable
+// or add relays for the session.
+type RelaysModal struct {
+	relays     []model.RelayStatus
+	cursor     int
+	adding     bool
+	input      textinput.Model
+	errorMsg   string
+	generation int
+	w          int
+}
+
+func NewRelaysModal() RelaysModal {
+	input := textinput.New()
+	input.Placeholder = "wss://relay.example.com"
+	input.CharLimit = 200
+
+	return RelaysModal{input: input}
+}
+
+func (r RelaysModal) Init() tea.Cmd {
+	return nil
+}
+
+// Open resets the modal and starts polling relay health while it is shown.
+func (r *RelaysModal) Open() tea.Cmd {
+	r.generation++
+	r.adding = false
+	r.errorMsg = ""
+	r.input.SetValue("")
+	r.input.Blur()
+	return tea.Batch(messages.LoadRelays, r.tick())
+}
+
+// Blur stops polling; ticks from the previous opening are ignored.
+func (r *RelaysModal) Blur() {
+	r.generation++
+	r.adding = false
+	r.input.Blur()
+}
+
+func (r RelaysModal) tick() tea.Cmd {
+	generation := r.generation
+	return tea.Tick(relayRefreshPeriod, func(time.Time) tea.Msg {
+		return messages.RelayTickMsg(generation)
+	})
+}
+
+func (r RelaysModal) Update(msg tea.Msg) (RelaysModal, tea.Cmd) {
+	switch msg := msg.(type) {
+	case messages.UpdateRelaysMsg:
+		r.relays = msg.Relays
+		r.errorMsg = msg.Error
+		r.cursor = utils.Clamp(0, max(len(r.relays)-1, 0), r.cursor)
+		return r, nil
+
+	case messages.RelayTickMsg:
+		if int(msg) != r.generation {
+			return r, nil
+		}
+		return r, tea.Batch(messages.LoadRelays, r.tick())
+
+	case tea.KeyMsg:
+		if r.adding {
+			return r.updateAdding(msg)
+		}
+
+		switch msg.String() {
+		case "j", "down":
+			r.cursor = utils.Clamp(0, max(len(r.relays)-1, 0), r.cursor+1)
+		case "k", "up":
+			r.cursor = utils.Clamp(0, max(len(r.relays)-1, 0), r.cursor-1)
+		case "r":
+			return r, r.action(messages.ReconnectRelay)
+		case "d":
+			return r, r.action(messages.ToggleRelay)
+		case "t":
+			return r, r.action(messages.AllowRelayAuth)
+		case "a":
+			r.adding = true
+			r.errorMsg = ""
+			return r, r.input.Focus()
+		case "esc", "q":
+			return r, messages.ExitModal
+		}
+	}
+
+	return r, nil
+}
+
+func (r RelaysModal) updateAdding(msg tea.KeyMsg) (RelaysModal, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		r.adding = false
+		r.input.Blur()
+		r.input.SetValue("")
+		return r, nil
+
+	case "enter":
+		url := strings.TrimSpace(r.input.Value())
+		if url == "" {
+			return r, nil
+		}
+		r.adding = false
+		r.input.Blur()
+		r.input.SetValue("")
+		return r, func() tea.Msg {
+			return messages.RelayActionMsg{Action: messages.AddRelay, URL: url}
+		}
+	}
+
+	var cmd tea.Cmd
+	r.input, cmd = r.input.Update(msg)
+	return r, cmd
+}
+
+func (r RelaysModal) action(action messages.RelayAction) tea.Cmd {
+	if len(r.relays) == 0 {
+		return nil
+	}
+
+	url := r.relays[r.cursor].URL
+	return func() tea.Msg {
+		return messages.RelayActionMsg{Action: action, URL: url}
+	}
+}
+
+func (r RelaysModal) View() string {
+	views := []string{relaysTitleStyle.Render(relaysTitle)}
+
+	if len(r.relays) == 0 {
+		views = append(views, relayRowStyle.Render("No relays configured."))
+	}
+
+	for i, relay := range r.relays {
+		views = append(views, r.relayView(relay, i == r.cursor))
+	}
+
+	if r.adding {
+		views = append(views, "", r.input.View(), relaysHelpStyle.Render(relaysAddHelp))
+	} else {
+		if r.errorMsg != "" {
+			views = append(views, "", defaultErrorStyle.Render(r.errorMsg))
+		}
+		views = append(views, relaysHelpStyle.Render(relaysHelp))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+func (r RelaysModal) relayView(relay model.RelayStatus, selected bool) string {
+	var state string
+	switch {
+	case relay.Disabled:
+		state = relayDisabledStyle.Render("○ disabled")
+	case relay.Connected:
+		state = relayConnectedStyle.Render("● connected")
+	default:
+		state = relayDownStyle.Render("● disconnected")
+	}
+
+	marker, rowStyle := "  ", relayRowStyle
+	if selected {
+		marker, rowStyle = "▸ ", relaySelectedStyle
+	}
+	row := fmt.Sprintf("%s  %s", rowStyle.Render(marker+relay.URL), state)
+	if relay.Auth != model.AuthNone {
+		row += "  " + authStyle(relay.Auth).Render(relay.Auth.String())
+	}
+
+	latency := "–"
+	if relay.Latency > 0 {
+		latency = fmt.Sprintf("%dms", relay.Latency.Milliseconds())
+	}
+	stats := relayStatsStyle.Render(fmt.Sprintf("latency %s • %s received • published %d ok / %d failed",
+		latency, utils.GetSingularPlural(fmt.Sprint(relay.EventsReceived), "event", "events"), relay.PublishOK, relay.PublishFailed))
+
+	lines := []string{row, stats}
+	if relay.LastError != "" {
+		lines = append(lines, relayErrorStyle.Render("last error: "+utils.TruncateString(relay.LastError, max(r.w-8, 20))))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func authStyle(state model.AuthState) lipgloss.Style {
+	switch state {
+	case model.AuthOK:
+		return relayConnectedStyle
+	case model.AuthFailed:
+		return relayDownStyle
+	default:
+		return relayNoticeStyle
+	}
+}
+
+func (r *RelaysModal) SetSize(w, h int) {
+	r.w = int((float64(w) * 2) / 3.0)
+	r.input.Width = max(r.w-10, 20)
+}
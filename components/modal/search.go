@@ -1,7 +1,6 @@
 package modal
 
 import (
-	"fmt"
 	"tuistr/components/colors"
 	"tuistr/components/messages"
 	"tuistr/utils"
@@ -32,15 +31,13 @@ func NewCommunitySearchModal() CommunitySearchModal {
 	searchTextInput.Placeholder = searchPlaceholder
 	searchTextInput.ShowSuggestions = true
 	searchTextInput.SetSuggestions(communitySuggestions)
-	searchTextInput.CharLimit = 30
+	searchTextInput.CharLimit = 200
 	searchTextInput.Validate = func(s string) error {
 		if s == "" {
 			return nil
 		}
-		if !utils.ValidateTopic(s) {
-			return fmt.Errorf("enter a topic id like t:linux")
-		}
-		return nil
+		_, err := utils.ParseCommunity(s)
+		return err
 	}
 
 	return CommunitySearchModal{
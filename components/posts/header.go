@@ -19,12 +19,24 @@ var (
 	defaultDescriptionStyle = lipgloss.NewStyle().
 				Bold(true).
 				Foreground(colors.AdaptiveColor(colors.Text))
+
+	sortStyle = lipgloss.NewStyle().
+			Padding(0, 2).
+			Foreground(colors.AdaptiveColor(colors.Text)).
+			Faint(true)
+
+	bannerStyle = lipgloss.NewStyle().
+			MarginTop(1).
+			Foreground(colors.AdaptiveColor(colors.Yellow)).
+			Italic(true)
 )
 
 type PostsHeader struct {
 	DescriptionStyle lipgloss.Style
 	Title            string
 	Description      string
+	Banner           string
+	Sort             string
 	W                int
 }
 
@@ -39,9 +51,17 @@ func (h *PostsHeader) SetSize(width, height int) {
 
 func (h PostsHeader) View() string {
 	titleView := titleStyle.Render(utils.TruncateString(h.Title, h.W))
+	if h.Sort != "" {
+		titleView = lipgloss.JoinHorizontal(lipgloss.Top, titleView, sortStyle.Render("sort: "+h.Sort))
+	}
 	descriptionView := h.DescriptionStyle.Render(h.Description)
 
-	joinedView := lipgloss.JoinVertical(lipgloss.Left, titleView, descriptionView)
+	views := []string{titleView, descriptionView}
+	if h.Banner != "" {
+		views = append(views, bannerStyle.Render(h.Banner))
+	}
+
+	joinedView := lipgloss.JoinVertical(lipgloss.Left, views...)
 	return headerContainerStyle.Render(joinedView)
 }
 
@@ -9,6 +9,11 @@ type postsKeyMap struct {
 	Load   key.Binding
 	New    key.Binding
 	Copy   key.Binding
+	Show   key.Binding
+	React  key.Binding
+	Sort   key.Binding
+	Edit   key.Binding
+	Delete key.Binding
 }
 
 var postsKeys = postsKeyMap{
@@ -30,6 +35,21 @@ var postsKeys = postsKeyMap{
 	Copy: key.NewBinding(
 		key.WithKeys("y"),
 		key.WithHelp("y", "copy nevent")),
+	React: key.NewBinding(
+		key.WithKeys("+", "-"),
+		key.WithHelp("+/-", "upvote/downvote")),
+	Sort: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "change sort")),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit own post")),
+	Delete: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "delete own post")),
+	Show: key.NewBinding(
+		key.WithKeys("."),
+		key.WithHelp(".", "show new posts")),
 }
 
 func (k postsKeyMap) ShortHelp() []key.Binding {
@@ -37,5 +57,5 @@ func (k postsKeyMap) ShortHelp() []key.Binding {
 }
 
 func (k postsKeyMap) FullHelp() []key.Binding {
-	return []key.Binding{k.Home, k.Search, k.Back, k.Load, k.New, k.Copy}
+	return []key.Binding{k.Home, k.Search, k.Back, k.Load, k.New, k.Copy, k.React, k.Sort, k.Show, k.Edit, k.Delete}
 }
@@ -1,9 +1,13 @@
 package posts
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 	"tuistr/client"
 	"tuistr/components/messages"
 	"tuistr/components/styles"
@@ -24,15 +28,21 @@ const (
 type PostsPage struct {
 	Community      string
 	posts          model.Posts
+	pending        []model.Post
+	live           <-chan model.Post
+	stopLive       context.CancelFunc
+	liveSince      time.Time
 	nostrClient    *client.NostrClient
 	header         PostsHeader
+	sortMode       model.SortMode
+	browsed        bool
 	list           list.Model
 	focus          bool
 	Home           bool
 	containerStyle lipgloss.Style
 }
 
-func NewPostsPage(nostrClient *client.NostrClient, home bool) PostsPage {
+func NewPostsPage(nostrClient *client.NostrClient, home bool, sortMode model.SortMode) PostsPage {
 	items := list.New(nil, NewPostsDelegate(), 0, 0)
 	items.SetShowTitle(false)
 	items.SetShowStatusBar(false)
@@ -43,6 +53,7 @@ func NewPostsPage(nostrClient *client.NostrClient, home bool) PostsPage {
 	items.AdditionalFullHelpKeys = postsKeys.FullHelp
 
 	header := NewPostsHeader()
+	header.Sort = sortMode.String()
 	if home {
 		header.SetContent(defaultHeaderTitle, defaultHeaderDescription)
 	}
@@ -53,6 +64,7 @@ func NewPostsPage(nostrClient *client.NostrClient, home bool) PostsPage {
 		list:           items,
 		nostrClient:    nostrClient,
 		header:         header,
+		sortMode:       sortMode,
 		Home:           home,
 		containerStyle: containerStyle,
 	}
@@ -81,12 +93,14 @@ func (p PostsPage) handleGlobalMessages(msg tea.Msg) (PostsPage, tea.Cmd) {
 	switch msg := msg.(type) {
 	case messages.LoadHomeMsg:
 		if p.Home {
+			p.liveSince = time.Now()
 			return p, p.loadHome()
 		}
 
 	case messages.LoadCommunityMsg:
 		if !p.Home {
 			community := string(msg)
+			p.liveSince = time.Now()
 			return p, p.loadCommunity(community)
 		}
 
@@ -100,15 +114,48 @@ func (p PostsPage) handleGlobalMessages(msg tea.Msg) (PostsPage, tea.Cmd) {
 		posts := model.Posts(msg)
 		if posts.IsHome == p.Home {
 			p.updatePosts(posts)
-			return p, messages.LoadingComplete
+			cmds := []tea.Cmd{messages.LoadingComplete, p.loadMeta(posts.Posts), p.startLive()}
+			if posts.Stale {
+				cmds = append(cmds, p.refreshPosts())
+			}
+			return p, tea.Batch(cmds...)
+		}
+
+	case messages.RefreshPostsMsg:
+		posts := model.Posts(msg)
+		if posts.IsHome == p.Home && (p.Home || posts.Community == p.Community) {
+			p.replacePosts(posts)
+			return p, p.loadMeta(posts.Posts)
 		}
 
 	case messages.AddMorePostsMsg:
 		posts := model.Posts(msg)
 		if posts.IsHome == p.Home {
 			p.addPosts(posts)
-			return p, messages.LoadingComplete
+			return p, tea.Batch(messages.LoadingComplete, p.loadMeta(posts.Posts))
+		}
+
+	case messages.LivePostMsg:
+		if msg.Source != p.live {
+			return p, nil
 		}
+		p.queuePost(msg.Post)
+		return p, messages.WaitForLivePost(p.live)
+
+	case messages.UpdateProfilesMsg:
+		p.updateAuthors(msg)
+
+	case messages.UpdateScoresMsg:
+		p.updateScores(msg)
+
+	case messages.UpdateRepliesMsg:
+		p.updateReplies(msg)
+
+	case messages.PostEditedMsg:
+		p.replaceRevised(msg.Revision)
+
+	case messages.EventDeletedMsg:
+		p.removePost(string(msg))
 	}
 
 	return p, nil
@@ -135,9 +182,22 @@ func (p PostsPage) handleFocusedMessages(msg tea.Msg) (PostsPage, tea.Cmd) {
 		case "L":
 			return p, messages.LoadMorePosts(p.Home)
 
+		case ".":
+			if len(p.pending) > 0 {
+				return p, p.showPending()
+			}
+			return p, nil
+
 		case "H":
 			return p, messages.LoadHome
 
+		case "o":
+			p.sortMode = p.sortMode.Next()
+			p.header.Sort = p.sortMode.String()
+			p.sortPosts()
+			p.list.Select(0)
+			return p, nil
+
 		case "n":
 			return p, func() tea.Msg {
 				community := p.Community
@@ -146,6 +206,26 @@ func (p PostsPage) handleFocusedMessages(msg tea.Msg) (PostsPage, tea.Cmd) {
 				}
 				return messages.ShowComposePostMsg{Community: community}
 			}
+		case "+", "-":
+			if len(p.posts.Posts) == 0 {
+				return p, nil
+			}
+			post := p.posts.Posts[p.list.Index()]
+			return p, messages.ReactToPost(post, keypress)
+
+		case "e":
+			if len(p.posts.Posts) == 0 {
+				return p, nil
+			}
+			return p, messages.ShowEditModal(p.posts.Posts[p.list.Index()])
+
+		case "x":
+			if len(p.posts.Posts) == 0 {
+				return p, nil
+			}
+			post := p.posts.Posts[p.list.Index()]
+			return p, messages.DeleteEvent(post.ID, post.PubKey, post.Kind)
+
 		case "y":
 			if len(p.posts.Posts) == 0 {
 				return p, nil
@@ -161,7 +241,11 @@ func (p PostsPage) handleFocusedMessages(msg tea.Msg) (PostsPage, tea.Cmd) {
 	}
 
 	var cmd tea.Cmd
+	index := p.list.Index()
 	p.list, cmd = p.list.Update(msg)
+	if p.list.Index() != index {
+		p.browsed = true
+	}
 	return p, cmd
 }
 
@@ -211,6 +295,11 @@ func (p *PostsPage) resizeComponents() {
 
 func (p *PostsPage) loadHome() tea.Cmd {
 	return func() tea.Msg {
+		// Show what we stored last time right away; relays refresh it afterwards
+		if posts, ok := p.nostrClient.GetStoredFeaturedPosts(); ok {
+			return messages.UpdatePostsMsg(posts)
+		}
+
 		posts, err := p.nostrClient.GetFeaturedPosts("")
 		if err != nil {
 			slog.Error(postsErrorText, "error", err)
@@ -250,6 +339,10 @@ func (p *PostsPage) loadMorePosts() tea.Cmd {
 
 func (p PostsPage) loadCommunity(community string) tea.Cmd {
 	return func() tea.Msg {
+		if posts, ok := p.nostrClient.GetStoredCommunityPosts(community); ok {
+			return messages.UpdatePostsMsg(posts)
+		}
+
 		posts, err := p.nostrClient.GetCommunityPosts(community, "")
 		if err != nil {
 			slog.Error(postsErrorText, "error", err)
@@ -260,8 +353,188 @@ func (p PostsPage) loadCommunity(community string) tea.Cmd {
 	}
 }
 
+// Fetch the first page from relays to replace posts served from the event store
+func (p *PostsPage) refreshPosts() tea.Cmd {
+	home, community := p.Home, p.Community
+	return func() tea.Msg {
+		var (
+			posts model.Posts
+			err   error
+		)
+
+		if home {
+			posts, err = p.nostrClient.GetFeaturedPosts("")
+		} else {
+			posts, err = p.nostrClient.GetCommunityPosts(community, "")
+		}
+
+		if err != nil {
+			slog.Warn("Could not refresh posts", "error", err)
+			return nil
+		}
+
+		return messages.RefreshPostsMsg(posts)
+	}
+}
+
+// Swap in refreshed posts, keeping the selected post under the cursor
+func (p *PostsPage) replacePosts(posts model.Posts) {
+	p.posts = posts
+	p.sortPosts()
+}
+
+// removePost drops a deleted post from the feed.
+func (p *PostsPage) removePost(id string) {
+	posts := slices.DeleteFunc(slices.Clone(p.posts.Posts), func(post model.Post) bool {
+		return post.ID == id
+	})
+	if len(posts) == len(p.posts.Posts) {
+		return
+	}
+	p.posts.Posts = posts
+	p.sortPosts()
+}
+
+// replaceRevised swaps a loaded post for its revision in place, reporting whether
+// the revised post was loaded. Replies to the old version still count. Only the
+// post's own author can revise it.
+func (p *PostsPage) replaceRevised(revision model.Post) bool {
+	i := slices.IndexFunc(p.posts.Posts, func(post model.Post) bool {
+		return post.PubKey == revision.PubKey && slices.Contains(revision.Revises, post.ID)
+	})
+	if i < 0 {
+		return false
+	}
+
+	revision.Replies = p.posts.Posts[i].Replies
+	p.posts.Posts = slices.Clone(p.posts.Posts)
+	p.posts.Posts[i] = revision
+	p.sortPosts()
+	return true
+}
+
+// Order the loaded posts by the current sort mode and rebuild the list, keeping
+// the selected post under the cursor
+func (p *PostsPage) sortPosts() {
+	var selectedID string
+	if item, ok := p.list.SelectedItem().(model.Post); ok {
+		selectedID = item.ID
+	}
+
+	posts := append([]model.Post{}, p.posts.Posts...)
+	model.SortPosts(posts, p.sortMode, time.Now())
+	p.posts.Posts = posts
+
+	var listItems []list.Item
+	selected := 0
+	for i, post := range posts {
+		if post.ID == selectedID {
+			selected = i
+		}
+		listItems = append(listItems, post)
+	}
+
+	p.list.SetItems(listItems)
+	p.list.Select(selected)
+	p.resizeComponents()
+}
+
+// Open a live subscription for the loaded feed, replacing the previous one. It
+// starts at liveSince so posts published while the feed loaded aren't missed.
+func (p *PostsPage) startLive() tea.Cmd {
+	if p.stopLive != nil {
+		p.stopLive()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stopLive = cancel
+	if p.Home {
+		p.live = p.nostrClient.SubscribeFeaturedPosts(ctx, p.liveSince)
+	} else {
+		p.live = p.nostrClient.SubscribeCommunityPosts(ctx, p.Community, p.liveSince)
+	}
+
+	return messages.WaitForLivePost(p.live)
+}
+
+// StopLive closes the live subscription while the page is hidden, so it stops
+// changing under other pages.
+func (p *PostsPage) StopLive() {
+	if p.stopLive == nil {
+		return
+	}
+
+	p.stopLive()
+	p.stopLive = nil
+	p.live = nil
+	p.liveSince = time.Now()
+}
+
+// ResumeLive reopens a subscription closed by StopLive, picking up from when it
+// stopped.
+func (p *PostsPage) ResumeLive() tea.Cmd {
+	if p.stopLive != nil || p.liveSince.IsZero() {
+		return nil
+	}
+	return p.startLive()
+}
+
+// Hold new posts back until the user asks for them so the list doesn't jump
+func (p *PostsPage) queuePost(post model.Post) {
+	for _, existing := range p.posts.Posts {
+		if existing.ID == post.ID {
+			return
+		}
+	}
+	for _, existing := range p.pending {
+		if existing.ID == post.ID {
+			return
+		}
+	}
+	// an edit isn't a new post, so it doesn't wait behind the banner
+	if post.Edited() && p.replaceRevised(post) {
+		return
+	}
+
+	p.pending = append(p.pending, post)
+	p.updateBanner()
+}
+
+// Prepend pending posts, keeping the selected post under the cursor
+func (p *PostsPage) showPending() tea.Cmd {
+	pending := p.pending
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.After(pending[j].CreatedAt)
+	})
+
+	p.pending = nil
+	p.updateBanner()
+
+	posts := p.posts
+	posts.Posts = append(append([]model.Post{}, pending...), p.posts.Posts...)
+	p.replacePosts(posts)
+
+	return p.loadMeta(pending)
+}
+
+func (p *PostsPage) updateBanner() {
+	switch n := len(p.pending); n {
+	case 0:
+		p.header.Banner = ""
+	case 1:
+		p.header.Banner = "1 new post — press . to show"
+	default:
+		p.header.Banner = fmt.Sprintf("%d new posts — press . to show", n)
+	}
+
+	// The banner changes the header height
+	p.resizeComponents()
+}
+
 func (p *PostsPage) updatePosts(posts model.Posts) {
 	p.posts = posts
+	p.pending = nil
+	p.header.Banner = ""
 
 	if posts.IsHome {
 		p.header.SetContent(defaultHeaderTitle, defaultHeaderDescription)
@@ -271,40 +544,149 @@ func (p *PostsPage) updatePosts(posts model.Posts) {
 	}
 
 	p.list.ResetSelected()
+	p.list.SetItems(nil)
+	p.browsed = false
 
-	var listItems []list.Item
-	for _, p := range posts.Posts {
-		listItems = append(listItems, p)
-	}
-	p.list.SetItems(listItems)
-
-	// Need to set size again when content loads so padding and margins are correct
-	p.resizeComponents()
+	// Sorting also sets the list items and resizes so padding and margins are correct
+	p.sortPosts()
 }
 
 func (p *PostsPage) addPosts(posts model.Posts) {
 	uniqueIds := make(map[string]bool)
 
-	p.posts.Posts = append(p.posts.Posts, posts.Posts...)
+	// Merge existing posts with new posts, avoiding duplicates
+	merged := make([]model.Post, 0, len(p.posts.Posts)+len(posts.Posts))
+	for _, post := range append(p.posts.Posts, posts.Posts...) {
+		if _, ok := uniqueIds[post.ID]; !ok {
+			merged = append(merged, post)
+			uniqueIds[post.ID] = true
+		}
+	}
+
+	p.posts.Posts = merged
 	p.posts.After = posts.After
+	p.sortPosts()
+}
 
-	// Merge existing posts with new posts, avoiding duplicates
-	var listItems []list.Item
-	for _, p := range p.posts.Posts {
-		if _, ok := uniqueIds[p.ID]; !ok {
-			listItems = append(listItems, p)
-			uniqueIds[p.ID] = true
+// Load everything shown next to a post that isn't part of the post event itself
+func (p *PostsPage) loadMeta(posts []model.Post) tea.Cmd {
+	return tea.Batch(p.loadProfiles(posts), p.loadScores(posts), p.loadReplies(posts))
+}
+
+func (p *PostsPage) loadReplies(posts []model.Post) tea.Cmd {
+	ids := make([]string, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.EventIDs()...)
+	}
+
+	return func() tea.Msg {
+		return messages.UpdateRepliesMsg(p.nostrClient.FetchReplyCounts(ids))
+	}
+}
+
+func (p *PostsPage) loadScores(posts []model.Post) tea.Cmd {
+	return func() tea.Msg {
+		return messages.UpdateScoresMsg(p.nostrClient.FetchPostScores(posts))
+	}
+}
+
+// Fetch author profiles, then check their NIP-05 identifiers. Each step refreshes
+// the list once it completes.
+func (p *PostsPage) loadProfiles(posts []model.Post) tea.Cmd {
+	pubKeys := make([]string, 0, len(posts))
+	for _, post := range posts {
+		pubKeys = append(pubKeys, post.PubKey)
+	}
+
+	return tea.Sequence(
+		func() tea.Msg {
+			return messages.UpdateProfilesMsg(p.nostrClient.FetchProfiles(pubKeys))
+		},
+		func() tea.Msg {
+			return messages.UpdateProfilesMsg(p.nostrClient.VerifyProfiles(pubKeys))
+		},
+	)
+}
+
+// Swap in profile names that arrived after the posts were rendered
+func (p *PostsPage) updateAuthors(profiles map[string]model.Profile) {
+	p.updatePostsWhere(func(post *model.Post) bool {
+		profile, ok := profiles[post.PubKey]
+		if !ok || (post.Author == profile.Name && post.Verified == profile.Verified) {
+			return false
+		}
+		post.Author, post.Verified = profile.Name, profile.Verified
+		return true
+	})
+}
+
+// Apply update to the loaded posts and list items, refreshing the list if any changed
+func (p *PostsPage) updatePostsWhere(update func(post *model.Post) bool) bool {
+	changed := false
+	for i := range p.posts.Posts {
+		if update(&p.posts.Posts[i]) {
+			changed = true
 		}
 	}
-	for _, p := range posts.Posts {
-		if _, ok := uniqueIds[p.ID]; !ok {
-			listItems = append(listItems, p)
-			uniqueIds[p.ID] = true
+
+	if !changed {
+		return false
+	}
+
+	items := p.list.Items()
+	for i, item := range items {
+		if post, ok := item.(model.Post); ok && update(&post) {
+			items[i] = post
 		}
 	}
+	p.list.SetItems(items)
+	return true
+}
 
-	p.list.SetItems(listItems)
+func (p *PostsPage) updateScores(scores map[string]int) {
+	changed := p.updatePostsWhere(func(post *model.Post) bool {
+		score, ok := scores[post.ID]
+		if !ok || post.Score == score {
+			return false
+		}
+		post.Score = score
+		return true
+	})
 
-	// Need to set size again when content loads so padding and margins are correct
-	p.resizeComponents()
+	if changed && p.sortMode != model.SortNew && p.sortMode != model.SortDiscussed {
+		p.resortUnbrowsed()
+	}
+}
+
+func (p *PostsPage) updateReplies(counts map[string]int) {
+	changed := p.updatePostsWhere(func(post *model.Post) bool {
+		// replies may point at any version of an edited post
+		count, found := 0, false
+		for _, id := range post.EventIDs() {
+			if n, ok := counts[id]; ok {
+				count, found = count+n, true
+			}
+		}
+		if !found || post.Replies == count {
+			return false
+		}
+		post.Replies = count
+		return true
+	})
+
+	if changed && (p.sortMode == model.SortHot || p.sortMode == model.SortDiscussed) {
+		p.resortUnbrowsed()
+	}
+}
+
+// Scores and reply counts arrive after the posts, so they reorder the feed only
+// until the user starts moving through it. After that the new order waits for
+// the next sort change, refresh or page load instead of shifting posts around
+// while they are being read.
+func (p *PostsPage) resortUnbrowsed() {
+	if p.browsed {
+		return
+	}
+	p.sortPosts()
+	p.list.Select(0)
 }
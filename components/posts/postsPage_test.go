@@ -0,0 +1,25 @@
+package posts
+
+import (
+	"testing"
+	"tuistr/model"
+)
+
+func TestReplaceRevisedNeedsTheSameAuthor(t *testing.T) {
+	page := NewPostsPage(nil, false, model.SortNew)
+	page.posts.Posts = []model.Post{{ID: "a", PubKey: "author", Replies: 3}}
+
+	if page.replaceRevised(model.Post{ID: "b", PubKey: "stranger", Revises: []string{"a"}}) {
+		t.Fatal("expected a stranger's revision to be ignored")
+	}
+	if got := page.posts.Posts; len(got) != 1 || got[0].ID != "a" {
+		t.Fatalf("expected the original post to stay, got %+v", got)
+	}
+
+	if !page.replaceRevised(model.Post{ID: "c", PubKey: "author", Revises: []string{"a"}}) {
+		t.Fatal("expected the author's revision to replace the post")
+	}
+	if got := page.posts.Posts; len(got) != 1 || got[0].ID != "c" || got[0].Replies != 3 {
+		t.Fatalf("expected the revision with the original's replies, got %+v", got)
+	}
+}
@@ -2,13 +2,16 @@ package components
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"tuistr/client"
 	"tuistr/components/comments"
 	"tuistr/components/messages"
 	"tuistr/components/modal"
 	"tuistr/components/posts"
 	"tuistr/config"
+	"tuistr/drafts"
 	"tuistr/model"
 	"tuistr/utils"
 
@@ -28,42 +31,81 @@ const (
 )
 
 type CommunitiesTui struct {
+	configuration config.Config
+	account       string
 	nostrClient   *client.NostrClient
 	homePage      posts.PostsPage
 	communityPage posts.PostsPage
 	commentsPage  comments.CommentsPage
 	modalManager  modal.ModalManager
+	drafts        *drafts.Store
 	popup         bool
 	initializing  bool
 	page          pageType
 	prevPage      pageType
 	loadingPage   pageType
 	startCmd      tea.Cmd
+	width         int
+	height        int
+
+	// the draft being published, dropped once the relays accept it
+	publishingDraft string
+}
+
+// accountSwitchedMsg carries the client built for the account the user switched to
+type accountSwitchedMsg struct {
+	account string
+	client  *client.NostrClient
+	err     error
 }
 
-func NewCommunitiesTui(configuration config.Config, communityArg, postID string) (CommunitiesTui, error) {
-	nostrClient, err := client.NewNostrClient(configuration)
+func NewCommunitiesTui(configuration config.Config, account, communityArg, postID string) (CommunitiesTui, error) {
+	accountConfig, err := configuration.WithAccount(account)
 	if err != nil {
 		return CommunitiesTui{}, err
 	}
 
-	homePage := posts.NewPostsPage(nostrClient, true)
-	communityPage := posts.NewPostsPage(nostrClient, false)
-	commentsPage := comments.NewCommentsPage(nostrClient)
-
-	modalManager := modal.NewModalManager()
+	nostrClient, err := client.NewNostrClient(accountConfig)
+	if err != nil {
+		return CommunitiesTui{}, err
+	}
 
-	startCmd := initialCommand(nostrClient, configuration.Communities, communityArg, postID)
+	draftStore, err := drafts.Open()
+	if err != nil {
+		// composing still works, the text just isn't kept
+		slog.Warn("Could not open drafts", "error", err)
+	}
 
-	return CommunitiesTui{
-		nostrClient:   nostrClient,
-		homePage:      homePage,
-		communityPage: communityPage,
-		commentsPage:  commentsPage,
-		modalManager:  modalManager,
+	r := CommunitiesTui{
+		configuration: configuration,
+		account:       account,
+		modalManager:  modal.NewModalManager(),
+		drafts:        draftStore,
 		initializing:  true,
-		startCmd:      startCmd,
-	}, nil
+	}
+	r.setClient(nostrClient, accountConfig.Communities)
+	r.startCmd = initialCommand(nostrClient, accountConfig.Communities, communityArg, postID)
+
+	return r, nil
+}
+
+// setClient points every page at nostrClient, starting them from scratch
+func (r *CommunitiesTui) setClient(nostrClient *client.NostrClient, communities config.CommunitiesConfig) {
+	sortMode := model.ParseSortMode(communities.Sort)
+	r.homePage.StopLive()
+	r.communityPage.StopLive()
+	r.commentsPage.StopLive()
+
+	r.nostrClient = nostrClient
+	r.homePage = posts.NewPostsPage(nostrClient, true, sortMode)
+	r.communityPage = posts.NewPostsPage(nostrClient, false, sortMode)
+	r.commentsPage = comments.NewCommentsPage(nostrClient)
+
+	if r.width > 0 {
+		r.homePage.SetSize(r.width, r.height)
+		r.communityPage.SetSize(r.width, r.height)
+		r.commentsPage.SetSize(r.width, r.height)
+	}
 }
 
 func initialCommand(client *client.NostrClient, communities config.CommunitiesConfig, communityArg, postID string) tea.Cmd {
@@ -130,8 +172,8 @@ func (r CommunitiesTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return r, cmd
 
 	case messages.GoBackMsg:
-		r.goBack()
-		return r, nil
+		cmd = r.goBack()
+		return r, cmd
 
 	case messages.LoadHomeMsg:
 		if r.page == HomePage && !r.initializing {
@@ -169,26 +211,94 @@ func (r CommunitiesTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case messages.ShowComposePostMsg:
 		r.focusModal()
-		return r, r.modalManager.SetComposePost(msg.Community)
+		draft, _ := r.drafts.Get(model.PostDraftKey(msg.Community))
+		return r, r.modalManager.SetComposePost(msg.Community, draft)
 
 	case messages.ShowReplyModalMsg:
 		r.focusModal()
-		return r, r.modalManager.SetComposeReply(model.Post(msg))
+		draft, _ := r.drafts.Get(model.ReplyDraftKey(msg.Post, msg.Parent))
+		return r, r.modalManager.SetComposeReply(msg.Post, msg.Parent, draft)
+
+	case messages.ShowEditModalMsg:
+		r.focusModal()
+		if msg.Post.PubKey == "" || msg.Post.PubKey != r.nostrClient.PubKey() {
+			return r, r.modalManager.SetError(client.ErrNotAuthor.Error())
+		}
+		draft, _ := r.drafts.Get(model.EditDraftKey(msg.Post))
+		return r, r.modalManager.SetComposeEdit(msg.Post, draft)
+
+	case messages.SaveDraftMsg:
+		if err := r.drafts.Save(model.Draft(msg)); err != nil {
+			slog.Warn("Could not save draft", "key", msg.Key, "error", err)
+		}
+		return r, nil
+
+	case messages.DeleteDraftMsg:
+		if err := r.drafts.Delete(string(msg)); err != nil {
+			slog.Warn("Could not delete draft", "key", string(msg), "error", err)
+		}
+		list := r.drafts.List()
+		return r, func() tea.Msg { return messages.DraftsMsg(list) }
+
+	case messages.LoadDraftsMsg:
+		list := r.drafts.List()
+		return r, func() tea.Msg { return messages.DraftsMsg(list) }
+
+	case messages.OpenDraftMsg:
+		r.focusModal()
+		draft := model.Draft(msg)
+		if draft.Edit {
+			return r, r.modalManager.SetComposeEdit(draft.Post, draft)
+		}
+		if draft.IsReply() {
+			return r, r.modalManager.SetComposeReply(draft.Post, draft.Parent, draft)
+		}
+		return r, r.modalManager.SetComposePost(draft.Community, draft)
 
 	case messages.SubmitPostMsg:
 		r.focusModal()
+		if r.nostrClient.SignerLocked() {
+			return r, r.modalManager.SetUnlocking(msg)
+		}
+		if !msg.Confirmed && !r.configuration.Compose.SkipPreview {
+			return r, tea.Batch(r.modalManager.SetLoading("preparing preview..."), previewPost(r.nostrClient, msg))
+		}
+		r.publishingDraft = msg.DraftKey
 		r.loadingPage = r.page
 		cmds = append(cmds, r.modalManager.SetLoading("publishing post..."), publishPost(r.nostrClient, msg))
 		return r, tea.Batch(cmds...)
 
 	case messages.SubmitReplyMsg:
 		r.focusModal()
+		if r.nostrClient.SignerLocked() {
+			return r, r.modalManager.SetUnlocking(msg)
+		}
+		if !msg.Confirmed && !r.configuration.Compose.SkipPreview {
+			return r, tea.Batch(r.modalManager.SetLoading("preparing preview..."), previewReply(r.nostrClient, msg))
+		}
+		r.publishingDraft = msg.DraftKey
 		r.loadingPage = CommentsPage
 		cmds = append(cmds, r.modalManager.SetLoading("publishing reply..."), publishReply(r.nostrClient, msg))
 		return r, tea.Batch(cmds...)
 
+	case messages.SubmitEditMsg:
+		r.focusModal()
+		if r.nostrClient.SignerLocked() {
+			return r, r.modalManager.SetUnlocking(msg)
+		}
+		if !msg.Confirmed && !r.configuration.Compose.SkipPreview {
+			return r, tea.Batch(r.modalManager.SetLoading("preparing preview..."), previewEdit(r.nostrClient, msg))
+		}
+		r.publishingDraft = msg.DraftKey
+		cmds = append(cmds, r.modalManager.SetLoading("publishing edit..."), editPost(r.nostrClient, msg))
+		return r, tea.Batch(cmds...)
+
+	case messages.PublishPreviewMsg:
+		return r, r.modalManager.SetPublishPreview(msg)
+
 	case messages.PostPublishedMsg:
 		r.popup = false
+		r.dropPublishedDraft()
 		cmds = append(cmds, r.modalManager.Blur())
 		target := utils.NormalizeCommunity(model.Post(msg).Community)
 		if target != "" {
@@ -200,12 +310,90 @@ func (r CommunitiesTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case messages.ReplyPublishedMsg:
 		r.popup = false
+		r.dropPublishedDraft()
 		cmds = append(cmds, r.modalManager.Blur(), messages.LoadThread(model.Post(msg.Post)))
 		return r, tea.Batch(cmds...)
 
+	case messages.PostEditedMsg:
+		// the pages swap in the revision below
+		r.popup = false
+		r.dropPublishedDraft()
+		r.focusActivePage()
+		cmds = append(cmds, r.modalManager.Blur())
+
 	case messages.PublishErrorMsg:
 		return r, r.modalManager.SetError(msg.ErrorMsg)
 
+	case messages.ReactMsg:
+		if r.nostrClient.SignerLocked() {
+			r.focusModal()
+			return r, r.modalManager.SetUnlocking(msg)
+		}
+		return r, react(r.nostrClient, msg)
+
+	case messages.DeleteEventMsg:
+		r.focusModal()
+		if r.nostrClient.SignerLocked() {
+			return r, r.modalManager.SetUnlocking(msg)
+		}
+		if msg.PubKey == "" || msg.PubKey != r.nostrClient.PubKey() {
+			return r, r.modalManager.SetError(client.ErrNotAuthor.Error())
+		}
+		if !msg.Confirmed {
+			confirm := msg
+			confirm.Confirmed = true
+			return r, r.modalManager.SetConfirm("Ask relays to delete this? Copies may survive elsewhere.", confirm)
+		}
+		return r, tea.Batch(r.modalManager.SetLoading("deleting..."), deleteEvent(r.nostrClient, msg))
+
+	case messages.EventDeletedMsg:
+		// the pages drop or blank the event below
+		r.popup = false
+		r.focusActivePage()
+		cmds = append(cmds, r.modalManager.Blur())
+
+	case messages.UnlockSignerMsg:
+		return r, unlockSigner(r.nostrClient, msg)
+
+	case messages.LoadRelaysMsg:
+		return r, loadRelays(r.nostrClient, "")
+
+	case messages.RelayActionMsg:
+		return r, relayAction(r.nostrClient, msg)
+
+	case messages.LoadRelayConfigMsg:
+		return r, loadRelayConfig(r.account)
+
+	case messages.SaveRelayConfigMsg:
+		return r, saveRelayConfig(r.nostrClient, r.account, msg)
+
+	case messages.LoadAccountsMsg:
+		names := r.configuration.AccountNames()
+		current := r.account
+		if current == "" {
+			current = config.DefaultAccount
+		}
+		return r, func() tea.Msg { return messages.AccountsMsg{Names: names, Current: current} }
+
+	case messages.SwitchAccountMsg:
+		r.focusModal()
+		r.loadingPage = r.page
+		cmd = r.modalManager.SetLoading(fmt.Sprintf("switching to %s...", string(msg)))
+		return r, tea.Batch(cmd, switchAccount(r.configuration, string(msg), r.nostrClient))
+
+	case accountSwitchedMsg:
+		if msg.err != nil {
+			slog.Error("Could not switch account", "account", msg.account, "error", msg.err)
+			return r, r.modalManager.SetError(fmt.Sprintf("Could not switch to %s: %v", msg.account, msg.err))
+		}
+		return r, r.useAccount(msg)
+
+	case messages.TestRelayMsg:
+		return r, testRelay(r.nostrClient, string(msg))
+
+	case messages.FetchRelayInfoMsg:
+		return r, fetchRelayInfo(r.nostrClient, string(msg))
+
 	case messages.CopyNeventMsg:
 		nevent, err := r.nostrClient.EncodeNevent(msg.Post)
 		if err != nil {
@@ -226,6 +414,7 @@ func (r CommunitiesTui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.WindowSizeMsg:
+		r.width, r.height = msg.Width, msg.Height
 		r.homePage.SetSize(msg.Width, msg.Height)
 		r.communityPage.SetSize(msg.Width, msg.Height)
 		r.commentsPage.SetSize(msg.Width, msg.Height)
@@ -277,32 +466,87 @@ func (r CommunitiesTui) View() string {
 	return ""
 }
 
-func (r *CommunitiesTui) goBack() {
+func (r *CommunitiesTui) goBack() tea.Cmd {
+	var cmd tea.Cmd
 	switch r.page {
 	case CommentsPage:
 		if r.prevPage == HomePage {
-			r.setPage(HomePage)
+			cmd = r.setPage(HomePage)
 		} else {
-			r.setPage(CommunityPage)
+			cmd = r.setPage(CommunityPage)
 		}
 	default:
-		r.setPage(HomePage)
+		cmd = r.setPage(HomePage)
 	}
 
 	r.focusActivePage()
+	return cmd
 }
 
-func (r *CommunitiesTui) setPage(page pageType) {
+// setPage switches pages. Only the visible page keeps a live subscription, so
+// the page being left stops and a feed shown again picks up where it stopped.
+func (r *CommunitiesTui) setPage(page pageType) tea.Cmd {
+	var cmd tea.Cmd
+	if page != r.page {
+		switch r.page {
+		case HomePage:
+			r.homePage.StopLive()
+		case CommunityPage:
+			r.communityPage.StopLive()
+		case CommentsPage:
+			r.commentsPage.StopLive()
+		}
+
+		switch page {
+		case HomePage:
+			cmd = r.homePage.ResumeLive()
+		case CommunityPage:
+			cmd = r.communityPage.ResumeLive()
+		}
+	}
+
 	r.page, r.prevPage = page, r.page
+	return cmd
 }
 
 func (r *CommunitiesTui) completeLoading() tea.Cmd {
 	r.initializing = false
 	r.popup = false
-	r.setPage(r.loadingPage)
+	cmd := r.setPage(r.loadingPage)
 	r.focusActivePage()
 
-	return r.modalManager.Blur()
+	return tea.Batch(cmd, r.modalManager.Blur())
+}
+
+// useAccount swaps in the new account's client and reloads its start page. The
+// old client goes away with its post and thread caches; the event store it shared
+// with the new one stays open.
+func (r *CommunitiesTui) useAccount(msg accountSwitchedMsg) tea.Cmd {
+	accountConfig, _ := r.configuration.WithAccount(msg.account)
+
+	previous := r.nostrClient
+	r.account = msg.account
+	r.setClient(msg.client, accountConfig.Communities)
+	previous.Close()
+
+	slog.Info("Switched account", "account", msg.account, "pubkey", msg.client.PubKey())
+
+	if community := accountConfig.Communities.Default; community != "" {
+		return messages.LoadCommunity(community)
+	}
+
+	// the home page is empty now, so load it even if it is the current page
+	var cmd tea.Cmd
+	r.loadingPage = HomePage
+	r.homePage, cmd = r.homePage.Update(messages.LoadHomeMsg{})
+	return cmd
+}
+
+func (r *CommunitiesTui) dropPublishedDraft() {
+	if err := r.drafts.Delete(r.publishingDraft); err != nil {
+		slog.Warn("Could not delete published draft", "key", r.publishingDraft, "error", err)
+	}
+	r.publishingDraft = ""
 }
 
 func (r *CommunitiesTui) focusModal() {
@@ -331,7 +575,7 @@ func (r *CommunitiesTui) focusActivePage() {
 
 func publishPost(client *client.NostrClient, msg messages.SubmitPostMsg) tea.Cmd {
 	return func() tea.Msg {
-		post, err := client.PublishPost(msg.Community, msg.Content)
+		post, err := client.PublishPost(msg.Community, msg.Title, msg.Content)
 		if err != nil {
 			return messages.PublishErrorMsg{ErrorMsg: err.Error()}
 		}
@@ -339,9 +583,207 @@ func publishPost(client *client.NostrClient, msg messages.SubmitPostMsg) tea.Cmd
 	}
 }
 
+func previewPost(client *client.NostrClient, msg messages.SubmitPostMsg) tea.Cmd {
+	return func() tea.Msg {
+		confirm := msg
+		confirm.Confirmed = true
+		preview, err := client.PreviewPost(msg.Community, msg.Title, msg.Content)
+		if err != nil {
+			return messages.PublishPreviewMsg{Confirm: confirm, Error: err.Error()}
+		}
+		return messages.PublishPreviewMsg{Preview: preview, Confirm: confirm}
+	}
+}
+
+func editPost(client *client.NostrClient, msg messages.SubmitEditMsg) tea.Cmd {
+	return func() tea.Msg {
+		revision, err := client.EditPost(msg.Post, msg.Title, msg.Content)
+		if err != nil {
+			return messages.PublishErrorMsg{ErrorMsg: err.Error()}
+		}
+		return messages.PostEditedMsg{Original: msg.Post, Revision: revision}
+	}
+}
+
+func previewEdit(client *client.NostrClient, msg messages.SubmitEditMsg) tea.Cmd {
+	return func() tea.Msg {
+		confirm := msg
+		confirm.Confirmed = true
+		preview, err := client.PreviewEdit(msg.Post, msg.Title, msg.Content)
+		if err != nil {
+			return messages.PublishPreviewMsg{Confirm: confirm, Error: err.Error()}
+		}
+		return messages.PublishPreviewMsg{Preview: preview, Confirm: confirm}
+	}
+}
+
+func previewReply(client *client.NostrClient, msg messages.SubmitReplyMsg) tea.Cmd {
+	return func() tea.Msg {
+		confirm := msg
+		confirm.Confirmed = true
+		preview, err := client.PreviewReply(msg.Post, msg.Parent, msg.Content)
+		if err != nil {
+			return messages.PublishPreviewMsg{Confirm: confirm, Error: err.Error()}
+		}
+		return messages.PublishPreviewMsg{Preview: preview, Confirm: confirm}
+	}
+}
+
+func react(client *client.NostrClient, msg messages.ReactMsg) tea.Cmd {
+	return func() tea.Msg {
+		score, err := client.React(msg.EventID, msg.PubKey, msg.Kind, msg.Content, msg.Versions)
+		if err != nil {
+			return messages.PublishErrorMsg{ErrorMsg: err.Error()}
+		}
+		return messages.UpdateScoresMsg{msg.EventID: score}
+	}
+}
+
+func deleteEvent(client *client.NostrClient, msg messages.DeleteEventMsg) tea.Cmd {
+	return func() tea.Msg {
+		if err := client.DeleteEvent(msg.EventID, msg.PubKey, msg.Kind); err != nil {
+			return messages.PublishErrorMsg{ErrorMsg: err.Error()}
+		}
+		return messages.EventDeletedMsg(msg.EventID)
+	}
+}
+
+func unlockSigner(client *client.NostrClient, msg messages.UnlockSignerMsg) tea.Cmd {
+	return func() tea.Msg {
+		if err := client.UnlockSigner(msg.Passphrase); err != nil {
+			slog.Warn("Could not unlock secret key", "error", err)
+			return messages.SignerUnlockedMsg{Retry: msg.Retry, Error: err.Error()}
+		}
+		return messages.SignerUnlockedMsg{Retry: msg.Retry}
+	}
+}
+
+func loadRelays(client *client.NostrClient, errorMsg string) tea.Cmd {
+	return func() tea.Msg {
+		return messages.UpdateRelaysMsg{Relays: client.RelayStatuses(), Error: errorMsg}
+	}
+}
+
+func relayAction(client *client.NostrClient, msg messages.RelayActionMsg) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		switch msg.Action {
+		case messages.ReconnectRelay:
+			err = client.ReconnectRelay(msg.URL)
+		case messages.ToggleRelay:
+			err = client.SetRelayEnabled(msg.URL, client.RelayDisabled(msg.URL))
+		case messages.AddRelay:
+			err = client.AddRelay(msg.URL)
+		case messages.AllowRelayAuth:
+			client.AllowRelayAuth(msg.URL)
+		}
+
+		errorMsg := ""
+		if err != nil {
+			slog.Warn("Relay action failed", "relay", msg.URL, "error", err)
+			errorMsg = fmt.Sprintf("%s: %v", msg.URL, err)
+		}
+		return loadRelays(client, errorMsg)()
+	}
+}
+
+// Build the client for account. A secretKeyCommand may prompt for a password, so
+// it gets the terminal while it runs.
+func switchAccount(configuration config.Config, account string, previous *client.NostrClient) tea.Cmd {
+	accountConfig, err := configuration.WithAccount(account)
+	if err != nil {
+		return func() tea.Msg { return accountSwitchedMsg{account: account, err: err} }
+	}
+
+	builder := &clientBuilder{configuration: accountConfig, previous: previous}
+	done := func(err error) tea.Msg {
+		return accountSwitchedMsg{account: account, client: builder.client, err: err}
+	}
+
+	if accountConfig.Nostr.SecretKeyCommand != "" && accountConfig.Nostr.Bunker == "" {
+		return tea.Exec(builder, done)
+	}
+	return func() tea.Msg { return done(builder.Run()) }
+}
+
+// clientBuilder creates a NostrClient as a tea.ExecCommand. The new client shares
+// the event store of the one it replaces.
+type clientBuilder struct {
+	configuration config.Config
+	previous      *client.NostrClient
+	client        *client.NostrClient
+}
+
+func (b *clientBuilder) Run() error {
+	nostrClient, err := b.previous.SwitchAccount(b.configuration)
+	b.client = nostrClient
+	return err
+}
+
+func (b *clientBuilder) SetStdin(io.Reader)  {}
+func (b *clientBuilder) SetStdout(io.Writer) {}
+func (b *clientBuilder) SetStderr(io.Writer) {}
+
+func loadRelayConfig(account string) tea.Cmd {
+	return func() tea.Msg {
+		configuration, err := config.LoadConfig()
+		if err != nil && !os.IsNotExist(err) {
+			return messages.RelayConfigMsg{Relays: configuration.Nostr.RelayModes(), Error: fmt.Sprintf("Could not read config: %v", err)}
+		}
+		if configuration, err = configuration.WithAccount(account); err != nil {
+			return messages.RelayConfigMsg{Error: err.Error()}
+		}
+		return messages.RelayConfigMsg{Relays: configuration.Nostr.RelayModes()}
+	}
+}
+
+// Write the relays to the config file, then switch the running client over to them
+func saveRelayConfig(client *client.NostrClient, account string, msg messages.SaveRelayConfigMsg) tea.Cmd {
+	modes := []config.RelayMode(msg)
+	return func() tea.Msg {
+		configuration, err := config.LoadConfig()
+		if err != nil && !os.IsNotExist(err) {
+			return messages.RelayConfigSavedMsg{Error: fmt.Sprintf("Could not read config: %v", err)}
+		}
+
+		if err := config.SaveRelays(configuration.RelayTable(account), modes); err != nil {
+			slog.Error("Could not save relays", "error", err)
+			return messages.RelayConfigSavedMsg{Error: fmt.Sprintf("Could not save config: %v", err)}
+		}
+
+		var nostrConfig config.NostrConfig
+		nostrConfig.SetRelayModes(modes)
+		if err := client.SetRelays(nostrConfig.ReadRelays(), nostrConfig.WriteRelays()); err != nil {
+			slog.Warn("Could not connect to every relay", "error", err)
+		}
+
+		return messages.RelayConfigSavedMsg{}
+	}
+}
+
+func testRelay(client *client.NostrClient, url string) tea.Cmd {
+	return func() tea.Msg {
+		latency, err := client.TestRelay(url)
+		if err != nil {
+			return messages.RelayTestedMsg{URL: url, Error: err.Error()}
+		}
+		return messages.RelayTestedMsg{URL: url, Latency: latency}
+	}
+}
+
+func fetchRelayInfo(client *client.NostrClient, url string) tea.Cmd {
+	return func() tea.Msg {
+		info, err := client.RelayInfo(url)
+		if err != nil {
+			return messages.RelayInfoMsg{URL: url, Error: err.Error()}
+		}
+		return messages.RelayInfoMsg{URL: url, Info: info}
+	}
+}
+
 func publishReply(client *client.NostrClient, msg messages.SubmitReplyMsg) tea.Cmd {
 	return func() tea.Msg {
-		comment, err := client.PublishReply(msg.Post, msg.Content)
+		comment, err := client.PublishReply(msg.Post, msg.Parent, msg.Content)
 		if err != nil {
 			return messages.PublishErrorMsg{ErrorMsg: err.Error()}
 		}
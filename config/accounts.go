@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultAccount names the identity configured directly under [nostr].
+const DefaultAccount = "default"
+
+var bareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// AccountConfig is a named identity under [accounts.<name>]. Unset fields fall
+// back to the [nostr] and [communities] tables.
+type AccountConfig struct {
+	SecretKey        string
+	SecretKeyFile    string
+	SecretKeyCommand string
+	Bunker           string
+	Relays           []string
+	ReadOnlyRelays   []string
+	WriteOnlyRelays  []string
+	Featured         []string
+}
+
+// HasSigner reports whether the account brings its own key or bunker.
+func (a AccountConfig) HasSigner() bool {
+	for _, value := range []string{a.SecretKey, a.SecretKeyFile, a.SecretKeyCommand, a.Bunker} {
+		if strings.TrimSpace(value) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRelays reports whether the account lists its own relays.
+func (a AccountConfig) HasRelays() bool {
+	return a.Relays != nil || a.ReadOnlyRelays != nil || a.WriteOnlyRelays != nil
+}
+
+// AccountNames returns the default account followed by the named ones, sorted.
+func (c Config) AccountNames() []string {
+	names := []string{DefaultAccount}
+	for name := range c.Accounts {
+		if name != DefaultAccount {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names[1:])
+	return names
+}
+
+// WithAccount returns the config as seen by the named account: its signer
+// replaces the default one, and its relays and featured communities replace the
+// defaults when set. An empty name is the default account.
+func (c Config) WithAccount(name string) (Config, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == DefaultAccount {
+		return c, nil
+	}
+
+	account, ok := c.Accounts[name]
+	if !ok {
+		return c, fmt.Errorf("unknown account %q (configured: %s)", name, strings.Join(c.AccountNames(), ", "))
+	}
+
+	if account.HasSigner() {
+		c.Nostr.SecretKey = account.SecretKey
+		c.Nostr.SecretKeyFile = account.SecretKeyFile
+		c.Nostr.SecretKeyCommand = account.SecretKeyCommand
+		c.Nostr.Bunker = account.Bunker
+	}
+
+	if account.HasRelays() {
+		c.Nostr.Relays = account.Relays
+		c.Nostr.ReadOnlyRelays = account.ReadOnlyRelays
+		c.Nostr.WriteOnlyRelays = account.WriteOnlyRelays
+	}
+
+	if account.Featured != nil {
+		c.Communities.Featured = account.Featured
+	}
+
+	return c, nil
+}
+
+// RelayTable returns the table that holds the relays the named account uses, so
+// the relay manager edits the right lists.
+func (c Config) RelayTable(name string) string {
+	account, ok := c.Accounts[name]
+	if name == DefaultAccount || !ok || !account.HasRelays() {
+		return "nostr"
+	}
+	return accountTable(name)
+}
+
+func accountTable(name string) string {
+	if bareKeyRegex.MatchString(name) {
+		return "accounts." + name
+	}
+	return fmt.Sprintf("accounts.%q", name)
+}
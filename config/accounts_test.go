@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+const accountsConfig = `
+[nostr]
+relays = ["wss://shared.example.com"]
+secretKeyFile = "~/.config/tuistr/nsec"
+
+[communities]
+featured = ["t:nostr"]
+
+[accounts.work]
+bunker = "bunker://abc?relay=wss://bunker.example.com"
+relays = ["wss://work.example.com"]
+featured = ["t:work"]
+
+[accounts.alt]
+secretKeyCommand = "pass show nostr/alt"
+`
+
+func loadAccountsConfig(t *testing.T) Config {
+	t.Helper()
+	var fromFile Config
+	meta, err := toml.Decode(accountsConfig, &fromFile)
+	if err != nil {
+		t.Fatalf("could not decode config: %v", err)
+	}
+	return mergeConfig(NewConfig(), fromFile, meta)
+}
+
+func TestWithAccountOverridesSignerRelaysAndFeatured(t *testing.T) {
+	cfg := loadAccountsConfig(t)
+
+	if names := cfg.AccountNames(); !slices.Equal(names, []string{DefaultAccount, "alt", "work"}) {
+		t.Fatalf("account names = %v", names)
+	}
+
+	work, err := cfg.WithAccount("work")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if work.Nostr.Bunker == "" || work.Nostr.SecretKeyFile != "" {
+		t.Fatalf("expected the bunker to replace the default key, got %+v", work.Nostr)
+	}
+	if !slices.Equal(work.Nostr.Relays, []string{"wss://work.example.com"}) || !slices.Equal(work.Communities.Featured, []string{"t:work"}) {
+		t.Fatalf("expected work relays and featured communities, got %+v", work)
+	}
+
+	alt, err := cfg.WithAccount("alt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alt.Nostr.SecretKeyCommand != "pass show nostr/alt" || alt.Nostr.SecretKeyFile != "" {
+		t.Fatalf("expected the alt command to replace the default key, got %+v", alt.Nostr)
+	}
+	if !slices.Equal(alt.Nostr.Relays, []string{"wss://shared.example.com"}) || !slices.Equal(alt.Communities.Featured, []string{"t:nostr"}) {
+		t.Fatalf("expected alt to inherit relays and featured communities, got %+v", alt)
+	}
+
+	if def, err := cfg.WithAccount(""); err != nil || def.Nostr.SecretKeyFile == "" {
+		t.Fatalf("expected the default account to be the base config, got %+v %v", def.Nostr, err)
+	}
+	if _, err := cfg.WithAccount("missing"); err == nil || !strings.Contains(err.Error(), "alt, work") {
+		t.Fatalf("expected unknown account error listing accounts, got %v", err)
+	}
+
+	if cfg.RelayTable("work") != "accounts.work" || cfg.RelayTable("alt") != "nostr" || cfg.RelayTable(DefaultAccount) != "nostr" {
+		t.Fatalf("unexpected relay tables")
+	}
+}
+
+func TestSaveRelaysIntoAccountTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), configFilename)
+	if err := os.WriteFile(path, []byte(accountsConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveRelays(path, "accounts.work", []RelayMode{{URL: "wss://new.example.com", Read: true, Write: true}}); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	var saved Config
+	if _, err := toml.Decode(string(data), &saved); err != nil {
+		t.Fatalf("saved config does not parse: %v", err)
+	}
+	if !slices.Equal(saved.Accounts["work"].Relays, []string{"wss://new.example.com"}) {
+		t.Fatalf("work relays = %v", saved.Accounts["work"].Relays)
+	}
+	if !slices.Equal(saved.Nostr.Relays, []string{"wss://shared.example.com"}) {
+		t.Fatalf("expected default relays to be untouched, got %v", saved.Nostr.Relays)
+	}
+}
@@ -14,9 +14,11 @@ const (
 )
 
 type Config struct {
-	Core        CoreConfig        `toml:"core"`
-	Nostr       NostrConfig       `toml:"nostr"`
-	Communities CommunitiesConfig `toml:"communities"`
+	Core        CoreConfig               `toml:"core"`
+	Nostr       NostrConfig              `toml:"nostr"`
+	Communities CommunitiesConfig        `toml:"communities"`
+	Compose     ComposeConfig            `toml:"compose"`
+	Accounts    map[string]AccountConfig `toml:"accounts"`
 }
 
 type CoreConfig struct {
@@ -24,15 +26,27 @@ type CoreConfig struct {
 }
 
 type NostrConfig struct {
-	Relays         []string
-	TimeoutSeconds int
-	Limit          int
-	SecretKey      string
+	Relays           []string
+	ReadOnlyRelays   []string
+	WriteOnlyRelays  []string
+	TimeoutSeconds   int
+	Limit            int
+	SecretKey        string
+	SecretKeyFile    string
+	SecretKeyCommand string
+	Bunker           string
+	Offline          bool
 }
 
 type CommunitiesConfig struct {
 	Featured []string
 	Default  string
+	Sort     string
+}
+
+type ComposeConfig struct {
+	// SkipPreview publishes straight from the composer
+	SkipPreview bool
 }
 
 func NewConfig() Config {
@@ -49,6 +63,7 @@ func NewConfig() Config {
 		Communities: CommunitiesConfig{
 			Featured: []string{"t:nostr", "t:farmstr", "t:foodstr"},
 			Default:  "",
+			Sort:     "new",
 		},
 	}
 }
@@ -102,6 +117,14 @@ func mergeConfig(left, right Config, meta toml.MetaData) Config {
 		left.Nostr.Relays = right.Nostr.Relays
 	}
 
+	if meta.IsDefined("nostr", "readOnlyRelays") {
+		left.Nostr.ReadOnlyRelays = right.Nostr.ReadOnlyRelays
+	}
+
+	if meta.IsDefined("nostr", "writeOnlyRelays") {
+		left.Nostr.WriteOnlyRelays = right.Nostr.WriteOnlyRelays
+	}
+
 	if meta.IsDefined("nostr", "timeoutSeconds") {
 		left.Nostr.TimeoutSeconds = right.Nostr.TimeoutSeconds
 	}
@@ -114,6 +137,22 @@ func mergeConfig(left, right Config, meta toml.MetaData) Config {
 		left.Nostr.SecretKey = right.Nostr.SecretKey
 	}
 
+	if meta.IsDefined("nostr", "secretKeyFile") {
+		left.Nostr.SecretKeyFile = right.Nostr.SecretKeyFile
+	}
+
+	if meta.IsDefined("nostr", "secretKeyCommand") {
+		left.Nostr.SecretKeyCommand = right.Nostr.SecretKeyCommand
+	}
+
+	if meta.IsDefined("nostr", "bunker") {
+		left.Nostr.Bunker = right.Nostr.Bunker
+	}
+
+	if meta.IsDefined("nostr", "offline") {
+		left.Nostr.Offline = right.Nostr.Offline
+	}
+
 	if meta.IsDefined("communities", "featured") {
 		left.Communities.Featured = right.Communities.Featured
 	}
@@ -122,6 +161,18 @@ func mergeConfig(left, right Config, meta toml.MetaData) Config {
 		left.Communities.Default = right.Communities.Default
 	}
 
+	if meta.IsDefined("communities", "sort") {
+		left.Communities.Sort = right.Communities.Sort
+	}
+
+	if meta.IsDefined("compose", "skipPreview") {
+		left.Compose.SkipPreview = right.Compose.SkipPreview
+	}
+
+	if meta.IsDefined("accounts") {
+		left.Accounts = right.Accounts
+	}
+
 	return left
 }
 
@@ -10,11 +10,27 @@ const defaultConfiguration = `
 
 [nostr]
 #relays = ["wss://relay.damus.io", "wss://nos.lol", "wss://relay.snort.social"]
+#readOnlyRelays = []  # only queried
+#writeOnlyRelays = []  # only published to
 #timeoutSeconds = 10
 #limit = 50
-#secretKey = ""  # nsec or hex, required to publish
+#secretKey = ""  # nsec, ncryptsec or hex, required to publish
+#secretKeyFile = ""  # read the key from a file instead, e.g. "~/.config/tuistr/nsec" (chmod 600)
+#secretKeyCommand = ""  # or from a command, e.g. "pass show nostr/nsec"
+#bunker = ""  # bunker://... NIP-46 remote signer, used instead of secretKey
+#offline = false  # browse only events stored under ~/.cache/tuistr
 
 [communities]
 #featured = ["t:nostr", "t:farmstr", "t:foodstr"]
 #default = ""  # leave empty to start on the featured feed
+#sort = "new"  # new, top, hot or discussed
+
+[compose]
+#skipPreview = false  # publish without confirming the preview first
+
+# Extra identities for --account and the A switcher; unset keys come from above
+#[accounts.work]
+#secretKeyCommand = "pass show nostr/work"
+#relays = ["wss://relay.damus.io"]
+#featured = ["t:nostr"]
 `
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"tuistr/utils"
+)
+
+// RelayMode is one relay from the config and whether we read from and/or
+// publish to it.
+type RelayMode struct {
+	URL   string
+	Read  bool
+	Write bool
+}
+
+// ReadRelays returns the relays used for queries and subscriptions.
+func (n NostrConfig) ReadRelays() []string {
+	return appendMissing(slices.Clone(n.Relays), n.ReadOnlyRelays...)
+}
+
+// WriteRelays returns the relays we publish to.
+func (n NostrConfig) WriteRelays() []string {
+	return appendMissing(slices.Clone(n.Relays), n.WriteOnlyRelays...)
+}
+
+// RelayModes merges the three relay lists into one entry per relay.
+func (n NostrConfig) RelayModes() []RelayMode {
+	var modes []RelayMode
+	add := func(urls []string, read, write bool) {
+		for _, url := range urls {
+			i := slices.IndexFunc(modes, func(m RelayMode) bool { return m.URL == url })
+			if i < 0 {
+				modes = append(modes, RelayMode{URL: url})
+				i = len(modes) - 1
+			}
+			modes[i].Read = modes[i].Read || read
+			modes[i].Write = modes[i].Write || write
+		}
+	}
+
+	add(n.Relays, true, true)
+	add(n.ReadOnlyRelays, true, false)
+	add(n.WriteOnlyRelays, false, true)
+	return modes
+}
+
+// SetRelayModes splits modes back into the three relay lists. Relays that are
+// neither read nor written are dropped.
+func (n *NostrConfig) SetRelayModes(modes []RelayMode) {
+	n.Relays, n.ReadOnlyRelays, n.WriteOnlyRelays = []string{}, []string{}, []string{}
+	for _, mode := range modes {
+		switch {
+		case mode.Read && mode.Write:
+			n.Relays = appendMissing(n.Relays, mode.URL)
+		case mode.Read:
+			n.ReadOnlyRelays = appendMissing(n.ReadOnlyRelays, mode.URL)
+		case mode.Write:
+			n.WriteOnlyRelays = appendMissing(n.WriteOnlyRelays, mode.URL)
+		}
+	}
+}
+
+func appendMissing(list []string, urls ...string) []string {
+	for _, url := range urls {
+		if !slices.Contains(list, url) {
+			list = append(list, url)
+		}
+	}
+	return list
+}
+
+// SaveRelays writes the relay lists to table ("nostr", or an account's table) in
+// the config file, leaving every other line, comment and unknown key as it was.
+func SaveRelays(table string, modes []RelayMode) error {
+	configDir, err := utils.GetConfigDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return err
+	}
+
+	return saveRelays(filepath.Join(configDir, configFilename), table, modes)
+}
+
+func saveRelays(path, table string, modes []RelayMode) error {
+	content := defaultConfiguration
+	mode := os.FileMode(0644)
+
+	if data, err := os.ReadFile(path); err == nil {
+		content = string(data)
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	var nostr NostrConfig
+	nostr.SetRelayModes(modes)
+
+	content = setTableKey(content, table, "relays", "", nostr.Relays, true)
+	content = setTableKey(content, table, "readOnlyRelays", "relays", nostr.ReadOnlyRelays, false)
+	content = setTableKey(content, table, "writeOnlyRelays", "readOnlyRelays", nostr.WriteOnlyRelays, false)
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(content), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// setTableKey sets key = values inside [table]. An existing assignment is replaced
+// in place, keeping its trailing comment; otherwise the key is added after the
+// after key (or the table header), or in a new table at the end. Empty lists are
+// only written when the key already exists or force is set.
+func setTableKey(content, table, key, after string, values []string, force bool) string {
+	lines := strings.Split(content, "\n")
+	assignment := fmt.Sprintf("%s = %s", key, formatStringArray(values))
+
+	header, end := -1, len(lines)
+	for i, line := range lines {
+		name, ok := tableHeader(line)
+		if !ok {
+			continue
+		}
+		if header >= 0 {
+			end = i
+			break
+		}
+		if name == table {
+			header = i
+		}
+	}
+
+	insertAt := header + 1
+	if header >= 0 {
+		for i := header + 1; i < end; i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			name, _, ok := strings.Cut(trimmed, "=")
+			if !ok || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+
+			if name = strings.TrimSpace(name); name == after {
+				last, _ := valueEnd(lines, i)
+				insertAt = last + 1
+			}
+			if name != key {
+				continue
+			}
+
+			last, comment := valueEnd(lines, i)
+			indent := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+			replacement := indent + assignment
+			if comment != "" {
+				replacement += "  " + comment
+			}
+
+			lines = slices.Replace(lines, i, last+1, replacement)
+			return strings.Join(lines, "\n")
+		}
+	}
+
+	if len(values) == 0 && !force {
+		return content
+	}
+
+	if header < 0 {
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + fmt.Sprintf("\n[%s]\n%s\n", table, assignment)
+	}
+
+	lines = slices.Insert(lines, insertAt, assignment)
+	return strings.Join(lines, "\n")
+}
+
+// tableHeader returns the table name if line is a [table] header.
+func tableHeader(line string) (string, bool) {
+	trimmed := strings.TrimSpace(stripComment(line))
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") || strings.HasPrefix(trimmed, "[[") {
+		return "", false
+	}
+	return strings.TrimSpace(trimmed[1 : len(trimmed)-1]), true
+}
+
+// valueEnd finds the line where the value assigned on lines[start] ends, following
+// multi-line arrays, and returns any comment after it.
+func valueEnd(lines []string, start int) (int, string) {
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		if i == start {
+			_, line, _ = strings.Cut(line, "=")
+		}
+
+		code := stripComment(line)
+		scanTOML(code, func(j int) bool {
+			switch code[j] {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			}
+			return true
+		})
+
+		if depth <= 0 {
+			return i, strings.TrimSpace(line[len(code):])
+		}
+	}
+	return len(lines) - 1, ""
+}
+
+// stripComment drops a # comment that isn't inside a string.
+func stripComment(line string) string {
+	end := len(line)
+	scanTOML(line, func(i int) bool {
+		if line[i] == '#' {
+			end = i
+			return false
+		}
+		return true
+	})
+	return line[:end]
+}
+
+// scanTOML calls visit with the index of every byte outside basic and literal
+// strings until visit returns false.
+func scanTOML(line string, visit func(i int) bool) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		default:
+			if !visit(i) {
+				return
+			}
+		}
+	}
+}
+
+func formatStringArray(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
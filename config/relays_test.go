@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestRelayModesRoundTrip(t *testing.T) {
+	nostr := NostrConfig{
+		Relays:          []string{"wss://a.example.com", "wss://b.example.com"},
+		ReadOnlyRelays:  []string{"wss://c.example.com", "wss://a.example.com"},
+		WriteOnlyRelays: []string{"wss://d.example.com"},
+	}
+
+	modes := nostr.RelayModes()
+	want := []RelayMode{
+		{URL: "wss://a.example.com", Read: true, Write: true},
+		{URL: "wss://b.example.com", Read: true, Write: true},
+		{URL: "wss://c.example.com", Read: true},
+		{URL: "wss://d.example.com", Write: true},
+	}
+	if !slices.Equal(modes, want) {
+		t.Fatalf("RelayModes() = %+v, want %+v", modes, want)
+	}
+
+	modes[1].Write = false
+	modes = append(modes, RelayMode{URL: "wss://e.example.com"})
+
+	var updated NostrConfig
+	updated.SetRelayModes(modes)
+	if !slices.Equal(updated.Relays, []string{"wss://a.example.com"}) ||
+		!slices.Equal(updated.ReadOnlyRelays, []string{"wss://b.example.com", "wss://c.example.com"}) ||
+		!slices.Equal(updated.WriteOnlyRelays, []string{"wss://d.example.com"}) {
+		t.Fatalf("unexpected relay lists %+v", updated)
+	}
+	if !slices.Equal(updated.ReadRelays(), []string{"wss://a.example.com", "wss://b.example.com", "wss://c.example.com"}) {
+		t.Fatalf("unexpected read relays %v", updated.ReadRelays())
+	}
+}
+
+func TestSaveRelaysPreservesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), configFilename)
+	original := `# my config
+[core]
+logLevel = "Debug"  # keep me
+
+[nostr]
+# relays I trust
+relays = [
+  "wss://old.example.com",  # legacy
+  "wss://other.example.com#x",
+]  # trailing note
+customKey = "unknown keys survive"
+writeOnlyRelays = ["wss://w.example.com"]
+
+[communities]
+featured = ["t:nostr"]
+`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	modes := []RelayMode{
+		{URL: "wss://new.example.com", Read: true, Write: true},
+		{URL: "wss://inbox.example.com", Read: true},
+	}
+	if err := saveRelays(path, "nostr", modes); err != nil {
+		t.Fatalf("saveRelays returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	for _, keep := range []string{"# my config", `logLevel = "Debug"  # keep me`, "# relays I trust", `customKey = "unknown keys survive"`, `featured = ["t:nostr"]`} {
+		if !strings.Contains(content, keep) {
+			t.Fatalf("expected %q to survive, got:\n%s", keep, content)
+		}
+	}
+	for _, gone := range []string{"old.example.com", "wss://w.example.com"} {
+		if strings.Contains(content, gone) {
+			t.Fatalf("expected %q to be replaced, got:\n%s", gone, content)
+		}
+	}
+	if !strings.Contains(content, "relays = [\"wss://new.example.com\"]  # trailing note\nreadOnlyRelays = [\"wss://inbox.example.com\"]\n") {
+		t.Fatalf("expected relays to be replaced in place, got:\n%s", content)
+	}
+
+	var cfg Config
+	if _, err := toml.Decode(content, &cfg); err != nil {
+		t.Fatalf("saved config does not parse: %v\n%s", err, content)
+	}
+	if !slices.Equal(cfg.Nostr.Relays, []string{"wss://new.example.com"}) ||
+		!slices.Equal(cfg.Nostr.ReadOnlyRelays, []string{"wss://inbox.example.com"}) ||
+		len(cfg.Nostr.WriteOnlyRelays) != 0 {
+		t.Fatalf("unexpected relays after save: %+v", cfg.Nostr)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected file mode to be kept, got %v (%v)", info.Mode().Perm(), err)
+	}
+}
+
+func TestSaveRelaysAddsMissingTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), configFilename)
+	if err := os.WriteFile(path, []byte("[core]\nlogLevel = \"Warn\""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveRelays(path, "nostr", []RelayMode{{URL: "wss://a.example.com", Read: true, Write: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Config
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Core.LogLevel != "Warn" || !slices.Equal(cfg.Nostr.Relays, []string{"wss://a.example.com"}) {
+		t.Fatalf("unexpected config after save: %+v", cfg)
+	}
+}
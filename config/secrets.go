@@ -0,0 +1,132 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"tuistr/utils"
+)
+
+var ErrMultipleSecretSources = errors.New("set only one of secretKey, secretKeyFile and secretKeyCommand")
+
+// LoadSecretKey returns the secret key from whichever of secretKey, secretKeyFile
+// or secretKeyCommand is set, or "" if none is. Only the first line of a file or
+// command output is used, so `pass show` style entries work as they are.
+func (n NostrConfig) LoadSecretKey() (string, error) {
+	sources := 0
+	for _, value := range []string{n.SecretKey, n.SecretKeyFile, n.SecretKeyCommand} {
+		if strings.TrimSpace(value) != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return "", ErrMultipleSecretSources
+	}
+
+	switch {
+	case strings.TrimSpace(n.SecretKeyCommand) != "":
+		return runSecretCommand(n.SecretKeyCommand)
+	case strings.TrimSpace(n.SecretKeyFile) != "":
+		data, err := os.ReadFile(expandHome(n.SecretKeyFile))
+		if err != nil {
+			return "", fmt.Errorf("could not read secretKeyFile: %w", err)
+		}
+		return firstLine(data), nil
+	default:
+		return strings.TrimSpace(n.SecretKey), nil
+	}
+}
+
+// runSecretCommand runs command through the shell. It gets the terminal so a
+// password manager can prompt for its own passphrase.
+func runSecretCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("secretKeyCommand failed: %w", err)
+	}
+
+	key := firstLine(out)
+	if key == "" {
+		return "", errors.New("secretKeyCommand printed nothing")
+	}
+	return key, nil
+}
+
+func firstLine(data []byte) string {
+	line, _, _ := bytes.Cut(bytes.TrimSpace(data), []byte("\n"))
+	return strings.TrimSpace(string(line))
+}
+
+func expandHome(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// SecretWarnings lists files holding a secret key that other users can read: the
+// config file when a secretKey is inline, and any secretKeyFile.
+func SecretWarnings(cfg Config) []string {
+	inline := []string{cfg.Nostr.SecretKey}
+	files := []string{cfg.Nostr.SecretKeyFile}
+	for _, name := range cfg.AccountNames()[1:] {
+		inline = append(inline, cfg.Accounts[name].SecretKey)
+		files = append(files, cfg.Accounts[name].SecretKeyFile)
+	}
+
+	var warnings []string
+
+	// an ncryptsec is useless without its passphrase, so it may sit in a shared file
+	if slices.ContainsFunc(inline, isPlainKey) {
+		if configDir, err := utils.GetConfigDir(); err == nil {
+			warnings = appendIfShared(warnings, filepath.Join(configDir, configFilename), "secretKey")
+		}
+	}
+
+	for _, path := range files {
+		if path = strings.TrimSpace(path); path != "" {
+			warnings = appendIfShared(warnings, expandHome(path), "secretKeyFile")
+		}
+	}
+
+	return warnings
+}
+
+func isPlainKey(key string) bool {
+	key = strings.TrimSpace(key)
+	return key != "" && !strings.HasPrefix(key, "ncryptsec1")
+}
+
+func appendIfShared(warnings []string, path, key string) []string {
+	if runtime.GOOS == "windows" {
+		// permission bits don't describe Windows ACLs
+		return warnings
+	}
+
+	// only read access leaks the key; write or execute bits alone don't
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0044 == 0 {
+		return warnings
+	}
+
+	return append(warnings, fmt.Sprintf("%s holds your %s but is readable by other users (mode %04o); run chmod 600 %s",
+		path, key, info.Mode().Perm(), path))
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadSecretKeySources(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "nsec")
+	if err := os.WriteFile(keyFile, []byte("  nsec1fromfile\nsecond line\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NostrConfig{SecretKeyFile: keyFile}.LoadSecretKey()
+	if err != nil || key != "nsec1fromfile" {
+		t.Fatalf("file key = %q, %v", key, err)
+	}
+
+	if runtime.GOOS != "windows" {
+		key, err = NostrConfig{SecretKeyCommand: "printf 'nsec1fromcommand\\nurl: example.com\\n'"}.LoadSecretKey()
+		if err != nil || key != "nsec1fromcommand" {
+			t.Fatalf("command key = %q, %v", key, err)
+		}
+
+		if _, err := (NostrConfig{SecretKeyCommand: "exit 3"}).LoadSecretKey(); err == nil {
+			t.Fatalf("expected a failing command to be an error")
+		}
+	}
+
+	key, err = NostrConfig{SecretKey: " nsec1inline "}.LoadSecretKey()
+	if err != nil || key != "nsec1inline" {
+		t.Fatalf("inline key = %q, %v", key, err)
+	}
+
+	if _, err := (NostrConfig{SecretKey: "nsec1inline", SecretKeyFile: keyFile}).LoadSecretKey(); !errors.Is(err, ErrMultipleSecretSources) {
+		t.Fatalf("expected multiple sources to be rejected, got %v", err)
+	}
+}
+
+func TestSecretWarningsForSharedFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not checked on windows")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	configDir := filepath.Join(home, ".config", "tuistr")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(configDir, configFilename)
+	if err := os.WriteFile(configPath, []byte("[nostr]\nsecretKey = \"nsec1inline\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(home, "nsec")
+	if err := os.WriteFile(keyFile, []byte("nsec1fromfile\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.Nostr.SecretKey = "nsec1inline"
+	cfg.Nostr.SecretKeyFile = "~/nsec"
+
+	warnings := SecretWarnings(cfg)
+	if len(warnings) != 2 || !strings.Contains(warnings[0], configPath) || !strings.Contains(warnings[1], keyFile) {
+		t.Fatalf("unexpected warnings %q", warnings)
+	}
+
+	os.Chmod(configPath, 0600)
+	os.Chmod(keyFile, 0604)
+	if warnings := SecretWarnings(cfg); len(warnings) != 1 || !strings.Contains(warnings[0], keyFile) {
+		t.Fatalf("expected a world readable key file to warn, got %q", warnings)
+	}
+
+	os.Chmod(keyFile, 0600)
+	if warnings := SecretWarnings(cfg); len(warnings) != 0 {
+		t.Fatalf("expected no warnings for private files, got %q", warnings)
+	}
+
+	// others may write to the file, but they can't read the key
+	os.Chmod(keyFile, 0620)
+	if warnings := SecretWarnings(cfg); len(warnings) != 0 {
+		t.Fatalf("expected no warnings for a file only the owner can read, got %q", warnings)
+	}
+
+	os.Chmod(configPath, 0644)
+	cfg.Nostr.SecretKey, cfg.Nostr.SecretKeyFile = "ncryptsec1encrypted", ""
+	if warnings := SecretWarnings(cfg); len(warnings) != 0 {
+		t.Fatalf("expected an ncryptsec not to warn, got %q", warnings)
+	}
+}
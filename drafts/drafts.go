@@ -0,0 +1,143 @@
+// Package drafts keeps unpublished posts and replies on disk so closing the
+// composer or a failed publish doesn't lose them.
+package drafts
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"tuistr/model"
+	"tuistr/utils"
+)
+
+const draftsFilename = "drafts.json"
+
+// Store holds the drafts in memory and rewrites the whole file on every change.
+// A nil Store keeps nothing.
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	drafts map[string]model.Draft
+}
+
+// Open loads the drafts kept under the state dir.
+func Open() (*Store, error) {
+	stateDir, err := utils.GetStateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(stateDir, 0750); err != nil {
+		return nil, err
+	}
+
+	return open(filepath.Join(stateDir, draftsFilename))
+}
+
+func open(path string) (*Store, error) {
+	store := &Store{
+		path:   path,
+		drafts: make(map[string]model.Draft),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var drafts []model.Draft
+	if err := json.Unmarshal(data, &drafts); err != nil {
+		return nil, err
+	}
+	for _, draft := range drafts {
+		store.drafts[draft.Key] = draft
+	}
+
+	return store, nil
+}
+
+func (s *Store) Get(key string) (model.Draft, bool) {
+	if s == nil {
+		return model.Draft{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	draft, ok := s.drafts[key]
+	return draft, ok
+}
+
+// List returns the drafts, most recently edited first.
+func (s *Store) List() []model.Draft {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sorted()
+}
+
+// Save keeps draft under its key. Saving blank content removes the draft instead.
+func (s *Store) Save(draft model.Draft) error {
+	if strings.TrimSpace(draft.Content) == "" {
+		return s.Delete(draft.Key)
+	}
+	if s == nil {
+		return nil
+	}
+
+	if draft.UpdatedAt.IsZero() {
+		draft.UpdatedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drafts[draft.Key] = draft
+	return s.write()
+}
+
+func (s *Store) Delete(key string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.drafts[key]; !ok {
+		return nil
+	}
+	delete(s.drafts, key)
+	return s.write()
+}
+
+func (s *Store) sorted() []model.Draft {
+	drafts := make([]model.Draft, 0, len(s.drafts))
+	for _, draft := range s.drafts {
+		drafts = append(drafts, draft)
+	}
+	sort.Slice(drafts, func(i, j int) bool {
+		return drafts[i].UpdatedAt.After(drafts[j].UpdatedAt)
+	})
+	return drafts
+}
+
+// write replaces the file through a rename so a crash can't leave half of it.
+func (s *Store) write() error {
+	data, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
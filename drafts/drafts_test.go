@@ -0,0 +1,68 @@
+package drafts
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"tuistr/model"
+)
+
+func TestStoreKeepsDraftsAcrossOpens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), draftsFilename)
+
+	store, err := open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	post := model.Post{ID: "root", PostTitle: "hello"}
+	older := model.Draft{Key: model.PostDraftKey("t:linux"), Community: "t:linux", Content: "first", UpdatedAt: time.Unix(100, 0)}
+	newer := model.Draft{Key: model.ReplyDraftKey(post, model.Comment{}), Post: post, Content: "second", UpdatedAt: time.Unix(200, 0)}
+	for _, draft := range []model.Draft{older, newer} {
+		if err := store.Save(draft); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	reopened, err := open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+
+	drafts := reopened.List()
+	if len(drafts) != 2 || drafts[0].Content != "second" || drafts[1].Content != "first" {
+		t.Fatalf("expected both drafts newest first, got %+v", drafts)
+	}
+	if draft, ok := reopened.Get(newer.Key); !ok || !draft.IsReply() || draft.Post.PostTitle != "hello" {
+		t.Fatalf("expected the reply draft to keep its thread, got %+v", draft)
+	}
+}
+
+func TestSavingBlankContentDeletesDraft(t *testing.T) {
+	store, err := open(filepath.Join(t.TempDir(), draftsFilename))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	key := model.PostDraftKey("t:nostr")
+	if err := store.Save(model.Draft{Key: key, Content: "gm"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := store.Save(model.Draft{Key: key, Content: "  \n"}); err != nil {
+		t.Fatalf("save blank: %v", err)
+	}
+
+	if _, ok := store.Get(key); ok {
+		t.Fatalf("expected blank draft to be removed")
+	}
+}
+
+func TestNilStoreKeepsNothing(t *testing.T) {
+	var store *Store
+	if err := store.Save(model.Draft{Key: "post:", Content: "gm"}); err != nil {
+		t.Fatalf("save on nil store: %v", err)
+	}
+	if drafts := store.List(); len(drafts) != 0 {
+		t.Fatalf("expected no drafts, got %+v", drafts)
+	}
+}
@@ -16,7 +16,9 @@ const version = "v0.3.9"
 
 type CliArgs struct {
 	community   string
+	account     string
 	postId      string
+	offline     bool
 	showVersion bool
 }
 
@@ -30,9 +32,16 @@ func main() {
 
 	defer logFile.Close()
 
+	for _, warning := range config.SecretWarnings(configuration) {
+		slog.Warn(warning)
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+
 	var args CliArgs
 	flag.StringVar(&args.postId, "event", "", "Event id")
 	flag.StringVar(&args.community, "community", "", "Community identifier (NIP-73)")
+	flag.StringVar(&args.account, "account", "", "Account from the [accounts] config tables")
+	flag.BoolVar(&args.offline, "offline", false, "Browse previously fetched events without connecting to relays")
 	flag.BoolVar(&args.showVersion, "version", false, "Version")
 	flag.Parse()
 
@@ -41,9 +50,14 @@ func main() {
 		os.Exit(0)
 	}
 
-	communities, err := components.NewCommunitiesTui(configuration, args.community, args.postId)
+	if args.offline {
+		configuration.Nostr.Offline = true
+	}
+
+	communities, err := components.NewCommunitiesTui(configuration, args.account, args.community, args.postId)
 	if err != nil {
 		slog.Error("Error initializing tuistr", "error", err)
+		fmt.Fprintf(os.Stderr, "Error initializing tuistr: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -9,20 +9,32 @@ import (
 type Comment struct {
 	ID        string
 	Author    string
+	Verified  bool
+	PubKey    string
+	Kind      int
+	ParentID  string
 	Text      string
 	Timestamp string
 	Depth     int
+	Score     int
+	Deleted   bool
 }
 
 type Comments struct {
 	PostID        string
 	PostTitle     string
 	PostAuthor    string
+	PostVerified  bool
+	PostPubKey    string
 	Community     string
 	PostText      string
 	PostUrl       string
 	PostTimestamp string
+	PostScore     int
+	PostDeleted   bool
+	PostEdited    bool
 	Expiry        time.Time
+	Stale         bool
 	Comments      []Comment
 }
 
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"tuistr/utils"
+)
+
+// Draft is unpublished compose text. Post drafts are keyed by the community the
+// composer was opened for, replies by the thread and the comment replied to, and
+// edits by the post being revised.
+type Draft struct {
+	Key       string
+	Community string
+	Subject   string
+	Post      Post
+	Parent    Comment
+	Edit      bool
+	Content   string
+	UpdatedAt time.Time
+}
+
+func PostDraftKey(community string) string {
+	return "post:" + utils.NormalizeCommunity(community)
+}
+
+func ReplyDraftKey(post Post, parent Comment) string {
+	if parent.ID == "" {
+		return "reply:" + post.ID
+	}
+	return "reply:" + post.ID + "/" + parent.ID
+}
+
+func EditDraftKey(post Post) string {
+	return "edit:" + post.ID
+}
+
+func (d Draft) IsReply() bool {
+	return d.Post.ID != "" && !d.Edit
+}
+
+// Title describes where the draft would be published.
+func (d Draft) Title() string {
+	if d.Edit {
+		return fmt.Sprintf("Edit %s", strings.TrimSpace(d.Post.PostTitle))
+	}
+	if d.IsReply() {
+		if d.Parent.ID != "" {
+			return fmt.Sprintf("Reply to %s in %s", d.Parent.Author, strings.TrimSpace(d.Post.PostTitle))
+		}
+		return fmt.Sprintf("Reply to %s", strings.TrimSpace(d.Post.PostTitle))
+	}
+	if community := utils.NormalizeCommunity(d.Community); community != "" {
+		return "Post to " + community
+	}
+	return "New post"
+}
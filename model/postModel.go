@@ -2,8 +2,10 @@ package model
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"tuistr/utils"
 )
 
 type Post struct {
@@ -11,12 +13,19 @@ type Post struct {
 	PostTitle    string
 	Content      string
 	Author       string
+	Verified     bool
 	PubKey       string
+	Kind         int
 	Community    string
 	FriendlyDate string
 	CreatedAt    time.Time
+	PublishedAt  time.Time
 	PostUrl      string
 	ThreadID     string
+	Score        int
+	Replies      int
+	Subject      string
+	Revises      []string
 }
 
 type Posts struct {
@@ -26,6 +35,36 @@ type Posts struct {
 	Posts       []Post
 	After       string
 	Expiry      time.Time
+	Stale       bool
+}
+
+// PublishPreview is an event before it is signed: how the thread view will show
+// it, the tags it carries and the relays it will be sent to.
+type PublishPreview struct {
+	Thread Comments
+	Tags   [][]string
+	Relays []string
+}
+
+// Edited reports whether the post is a revision of earlier versions, which
+// Revises lists oldest first.
+func (p Post) Edited() bool {
+	return len(p.Revises) > 0
+}
+
+// Posted is when the first version of the post was published. A revision's
+// CreatedAt is when it was edited.
+func (p Post) Posted() time.Time {
+	if p.PublishedAt.IsZero() {
+		return p.CreatedAt
+	}
+	return p.PublishedAt
+}
+
+// EventIDs returns the post's id and the ids of the versions it replaces, which
+// replies and reactions may still point at.
+func (p Post) EventIDs() []string {
+	return append([]string{p.ID}, p.Revises...)
 }
 
 func (p Post) Title() string {
@@ -39,7 +78,15 @@ func (p Post) Description() string {
 		sb.WriteString("  ")
 	}
 
+	fmt.Fprintf(&sb, "%s  ", utils.GetSingularPlural(strconv.Itoa(p.Score), "point", "points"))
+	fmt.Fprintf(&sb, "%s  ", utils.GetSingularPlural(strconv.Itoa(p.Replies), "comment", "comments"))
 	fmt.Fprintf(&sb, "posted %s by %s", p.FriendlyDate, p.Author)
+	if p.Verified {
+		sb.WriteString(" ✓")
+	}
+	if p.Edited() {
+		sb.WriteString("  (edited)")
+	}
 	return sb.String()
 }
 
@@ -0,0 +1,7 @@
+package model
+
+type Profile struct {
+	Name     string
+	Nip05    string
+	Verified bool
+}
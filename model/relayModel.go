@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// AuthState is how far NIP-42 authentication with a relay got.
+type AuthState int
+
+const (
+	AuthNone AuthState = iota
+	AuthRequired
+	AuthPending
+	AuthOK
+	AuthFailed
+)
+
+var authStateNames = []string{"", "auth required", "authenticating", "authenticated", "auth failed"}
+
+func (a AuthState) String() string {
+	if int(a) < 0 || int(a) >= len(authStateNames) {
+		return ""
+	}
+	return authStateNames[a]
+}
+
+// RelayStatus is a snapshot of one relay's health for this session.
+type RelayStatus struct {
+	URL            string
+	Connected      bool
+	Disabled       bool
+	Auth           AuthState
+	AuthAllowed    bool
+	LastError      string
+	Latency        time.Duration
+	EventsReceived int
+	PublishOK      int
+	PublishFailed  int
+}
+
+// RelayInfo is the part of a relay's NIP-11 document worth showing.
+type RelayInfo struct {
+	URL              string
+	Name             string
+	Description      string
+	Software         string
+	SupportedNIPs    []int
+	MaxMessageLength int
+	MaxSubscriptions int
+	MaxLimit         int
+	MaxContentLength int
+	AuthRequired     bool
+	PaymentRequired  bool
+	RestrictedWrites bool
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type SortMode int
+
+const (
+	SortNew SortMode = iota
+	SortTop
+	SortHot
+	SortDiscussed
+)
+
+var sortModeNames = []string{"new", "top", "hot", "discussed"}
+
+// hotGravity controls how quickly posts sink with age (same idea as Hacker News).
+const hotGravity = 1.8
+
+func (m SortMode) String() string {
+	if int(m) < 0 || int(m) >= len(sortModeNames) {
+		return sortModeNames[SortNew]
+	}
+	return sortModeNames[m]
+}
+
+// Next cycles through the sort modes.
+func (m SortMode) Next() SortMode {
+	return SortMode((int(m) + 1) % len(sortModeNames))
+}
+
+// ParseSortMode reads a sort mode name, defaulting to new for unknown values.
+func ParseSortMode(s string) SortMode {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "most discussed" || s == "most-discussed" {
+		return SortDiscussed
+	}
+	for i, name := range sortModeNames {
+		if name == s {
+			return SortMode(i)
+		}
+	}
+	return SortNew
+}
+
+// HotScore ranks by engagement, decayed by age in hours.
+func (p Post) HotScore(now time.Time) float64 {
+	age := max(now.Sub(p.Posted()).Hours(), 0)
+	return float64(p.Score+p.Replies) / math.Pow(age+2, hotGravity)
+}
+
+// SortPosts orders posts in place. Ties fall back to newest first.
+func SortPosts(posts []Post, mode SortMode, now time.Time) {
+	newer := func(i, j int) bool {
+		return posts[i].Posted().After(posts[j].Posted())
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		switch mode {
+		case SortTop:
+			if posts[i].Score != posts[j].Score {
+				return posts[i].Score > posts[j].Score
+			}
+		case SortHot:
+			hi, hj := posts[i].HotScore(now), posts[j].HotScore(now)
+			if hi != hj {
+				return hi > hj
+			}
+		case SortDiscussed:
+			if posts[i].Replies != posts[j].Replies {
+				return posts[i].Replies > posts[j].Replies
+			}
+		}
+		return newer(i, j)
+	})
+}
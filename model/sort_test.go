@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSortMode(t *testing.T) {
+	cases := map[string]SortMode{
+		"":               SortNew,
+		"new":            SortNew,
+		"Top":            SortTop,
+		" hot ":          SortHot,
+		"discussed":      SortDiscussed,
+		"most discussed": SortDiscussed,
+		"bogus":          SortNew,
+	}
+	for input, want := range cases {
+		if got := ParseSortMode(input); got != want {
+			t.Errorf("ParseSortMode(%q) = %v, want %v", input, got, want)
+		}
+	}
+
+	if SortDiscussed.Next() != SortNew {
+		t.Errorf("expected sort modes to wrap around")
+	}
+}
+
+func TestSortPosts(t *testing.T) {
+	now := time.Now()
+	posts := func() []Post {
+		return []Post{
+			{ID: "fresh", CreatedAt: now.Add(-time.Hour), Score: 3, Replies: 1},
+			{ID: "old-popular", CreatedAt: now.Add(-72 * time.Hour), Score: 40, Replies: 2},
+			{ID: "chatty", CreatedAt: now.Add(-5 * time.Hour), Score: 1, Replies: 12},
+		}
+	}
+
+	cases := []struct {
+		mode SortMode
+		want []string
+	}{
+		{SortNew, []string{"fresh", "chatty", "old-popular"}},
+		{SortTop, []string{"old-popular", "fresh", "chatty"}},
+		{SortHot, []string{"fresh", "chatty", "old-popular"}},
+		{SortDiscussed, []string{"chatty", "old-popular", "fresh"}},
+	}
+
+	for _, tc := range cases {
+		sorted := posts()
+		SortPosts(sorted, tc.mode, now)
+		for i, id := range tc.want {
+			if sorted[i].ID != id {
+				t.Errorf("%s: position %d = %s, want %s", tc.mode, i, sorted[i].ID, id)
+			}
+		}
+	}
+}
+
+func TestSortPostsUsesFirstPublished(t *testing.T) {
+	now := time.Now()
+	posts := []Post{
+		{ID: "new", CreatedAt: now.Add(-time.Hour)},
+		{ID: "edited", CreatedAt: now, PublishedAt: now.Add(-48 * time.Hour), Revises: []string{"original"}},
+	}
+
+	SortPosts(posts, SortNew, now)
+	if posts[0].ID != "new" {
+		t.Fatalf("expected an old post's edit not to sort as new, got %v", posts)
+	}
+	if hot := posts[1].HotScore(now); hot != 0 {
+		t.Fatalf("expected hot score without engagement to be 0, got %f", hot)
+	}
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EditorCommand returns the command that opens path in the user's editor, taken
+// from $VISUAL, then $EDITOR. Either may carry flags, e.g. "code --wait".
+func EditorCommand(path string) *exec.Cmd {
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(editor) == 0 {
+		if runtime.GOOS == "windows" {
+			editor = []string{"notepad"}
+		} else {
+			editor = []string{"vi"}
+		}
+	}
+
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
@@ -11,7 +13,12 @@ import (
 	"github.com/atotto/clipboard"
 )
 
+// NormalizeCommunity returns the canonical form of a community id. Ids that don't
+// parse are only trimmed and lowercased.
 func NormalizeCommunity(community string) string {
+	if normalized, err := ParseCommunity(community); err == nil {
+		return normalized
+	}
 	return strings.TrimSpace(strings.ToLower(community))
 }
 
@@ -25,6 +32,31 @@ func TruncateString(s string, w int) string {
 	return fmt.Sprintf("%s...", s[:w-3])
 }
 
+// StripControl removes C0 and C1 control characters other than newlines, so
+// text from relays can't smuggle terminal escape sequences into the UI.
+func StripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' {
+			return r
+		}
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// SanitizeLine makes text from the network safe to print on one line: control
+// characters are dropped, whitespace runs collapse to single spaces and the
+// result is clamped to maxRunes, ending in "…" when it had to be cut.
+func SanitizeLine(s string, maxRunes int) string {
+	s = strings.Join(strings.Fields(StripControl(strings.ReplaceAll(s, "\t", " "))), " ")
+	if runes := []rune(s); maxRunes > 0 && len(runes) > maxRunes {
+		s = strings.TrimSpace(string(runes[:maxRunes-1])) + "…"
+	}
+	return s
+}
+
 func Clamp(min, max, val int) int {
 	if val < min {
 		return min
@@ -71,14 +103,110 @@ func ShortenPubKey(pk string) string {
 	return fmt.Sprintf("%s...%s", pk[:6], pk[len(pk)-4:])
 }
 
-var topicRegexp = regexp.MustCompile(`^t:[a-z0-9][a-z0-9:_-]*$`)
+var (
+	topicRegexp   = regexp.MustCompile(`^t:[a-z0-9][a-z0-9:_-]*$`)
+	geohashRegexp = regexp.MustCompile(`^[0123456789bcdefghjkmnpqrstuvwxyz]{1,12}$`)
+
+	ErrInvalidTopic   = errors.New("enter a topic id like t:linux")
+	ErrInvalidURL     = errors.New("enter a relay or web url like u:wss://nos.lol")
+	ErrInvalidGeohash = errors.New("enter a geohash like g:dr5regw3pg")
+	ErrUnknownPrefix  = errors.New("community ids start with t:, u: or g:")
+)
 
-// ValidateTopic ensures we only allow topic-style NIP-73 ids for now.
+// ValidateTopic reports whether community is a topic-style NIP-73 id.
 func ValidateTopic(community string) bool {
-	community = NormalizeCommunity(community)
+	community = strings.TrimSpace(strings.ToLower(community))
 	return topicRegexp.MatchString(community)
 }
 
+// ValidateCommunity reports whether community is a topic, url or geohash id.
+func ValidateCommunity(community string) bool {
+	_, err := ParseCommunity(community)
+	return err == nil
+}
+
+// ParseCommunity validates a t:, u: or g: community id and normalizes it so the
+// same community always produces the same I tag:
+//   - topics are lowercased
+//   - urls get a lowercase scheme and host, no default port, no fragment and no
+//     lone trailing slash; path and query keep their case
+//   - geohashes are lowercased
+func ParseCommunity(community string) (string, error) {
+	community = strings.TrimSpace(community)
+	prefix, value, ok := strings.Cut(community, ":")
+	if !ok {
+		return "", ErrUnknownPrefix
+	}
+
+	switch strings.ToLower(prefix) {
+	case "t":
+		topic := "t:" + strings.ToLower(value)
+		if !topicRegexp.MatchString(topic) {
+			return "", ErrInvalidTopic
+		}
+		return topic, nil
+
+	case "u":
+		normalized, err := normalizeURL(value)
+		if err != nil {
+			return "", ErrInvalidURL
+		}
+		return "u:" + normalized, nil
+
+	case "g":
+		geohash := strings.ToLower(value)
+		if !geohashRegexp.MatchString(geohash) {
+			return "", ErrInvalidGeohash
+		}
+		return "g:" + geohash, nil
+	}
+
+	return "", ErrUnknownPrefix
+}
+
+var defaultPorts = map[string]string{"http": "80", "ws": "80", "https": "443", "wss": "443"}
+
+func normalizeURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := defaultPorts[u.Scheme]; !ok || u.Hostname() == "" || u.User != nil {
+		return "", ErrInvalidURL
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != defaultPorts[u.Scheme] {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	u.Host = host
+
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "/" {
+		u.Path = ""
+	}
+	u.RawPath = ""
+
+	return u.String(), nil
+}
+
+// CommunityKind returns the NIP-73 K tag value for a community identifier.
+func CommunityKind(community string) string {
+	switch {
+	case strings.HasPrefix(community, "u:"):
+		return "web"
+	case strings.HasPrefix(community, "g:"):
+		return "geo"
+	default:
+		return "#"
+	}
+}
+
 // CopyToClipboard writes text to the system clipboard.
 func CopyToClipboard(text string) error {
 	if strings.TrimSpace(text) == "" {
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -48,12 +49,82 @@ func TestValidateTopic(t *testing.T) {
 	}
 }
 
+func TestParseCommunity(t *testing.T) {
+	valid := map[string]string{
+		" T:NoStr ":                               "t:nostr",
+		"u:wss://relay.damus.io":                  "u:wss://relay.damus.io",
+		"U:WSS://Relay.Damus.io/":                 "u:wss://relay.damus.io",
+		"u:https://Example.com:443/Blog/Post#top": "u:https://example.com/Blog/Post",
+		"u:https://example.com:8443/a?b=C":        "u:https://example.com:8443/a?b=C",
+		"g:DR5REGW3PG":                            "g:dr5regw3pg",
+		"g:u":                                     "g:u",
+	}
+	for input, want := range valid {
+		got, err := ParseCommunity(input)
+		if err != nil {
+			t.Fatalf("ParseCommunity(%q) returned error %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("ParseCommunity(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	invalid := []string{
+		"", "nostr", "x:foo", "t:", "u:", "u:relay.damus.io", "u:ftp://example.com",
+		"u:https://user@example.com", "g:", "g:dr5ra", "g:1234567890123",
+	}
+	for _, input := range invalid {
+		if got, err := ParseCommunity(input); err == nil {
+			t.Fatalf("expected %q to be invalid, got %q", input, got)
+		}
+	}
+}
+
 func TestCopyToClipboardEmpty(t *testing.T) {
 	if err := CopyToClipboard(""); err == nil {
 		t.Fatalf("expected error copying empty text")
 	}
 }
 
+func TestSanitizeLine(t *testing.T) {
+	tests := []struct {
+		s        string
+		maxRunes int
+		want     string
+	}{
+		{"  plain  ", 10, "plain"},
+		{"two\nlines\tand  spaces", 40, "two lines and spaces"},
+		{"\x1b]0;owned\x07relay\u009b2J", 40, "]0;ownedrelay2J"},
+		{"a long relay name", 7, "a long…"},
+		{"世界世界世界", 4, "世界世…"},
+		{"no limit", 0, "no limit"},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeLine(tt.s, tt.maxRunes); got != tt.want {
+			t.Errorf("SanitizeLine(%q, %d) = %q, want %q", tt.s, tt.maxRunes, got, tt.want)
+		}
+	}
+}
+
+func TestStripControl(t *testing.T) {
+	cases := map[string]string{
+		"plain":                   "plain",
+		"two\nlines":              "two\nlines",
+		"\x1b]52;c;aGk=\x07title": "]52;c;aGk=title",
+		"\x1b[2Jcleared":          "[2Jcleared",
+		"tab\tand\rreturn":        "tabandreturn",
+		"c1\u009b31mred\u0085":    "c131mred",
+		"wide 世界 and emoji 🎉\x7f": "wide 世界 and emoji 🎉",
+	}
+
+	for in, want := range cases {
+		if got := StripControl(in); got != want {
+			t.Fatalf("StripControl(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 func TestClamp(t *testing.T) {
 	tests := []struct {
 		min  int
@@ -121,3 +192,18 @@ func TestShortenPubKey(t *testing.T) {
 		t.Fatalf("expected shortened key, got %s", long)
 	}
 }
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+
+	cmd := EditorCommand("/tmp/post.md")
+	if got := strings.Join(cmd.Args, " "); got != "code --wait /tmp/post.md" {
+		t.Fatalf("expected editor flags before the file, got %q", got)
+	}
+
+	t.Setenv("VISUAL", "nvim")
+	if got := EditorCommand("/tmp/post.md").Args[0]; got != "nvim" {
+		t.Fatalf("expected $VISUAL to win over $EDITOR, got %q", got)
+	}
+}